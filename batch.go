@@ -0,0 +1,721 @@
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+/*
+
+This module contains the batch sampling interfaces that fill a whole slice of
+noise values with one call. Sampling through NoiseyGet2D costs an interface
+call per value for every module in the chain; a module implementing
+NoiseyBatch2D makes one call per batch to each of its sources instead.
+
+A quick sample of sampling a grid of fBm noise looks like this:
+
+  perlin := noisey.NewPerlinGeneratorSeed(1)
+  fbm := noisey.NewFBMGenerator2D(&perlin, 4, 0.5, 2.0, 1.0)
+  values := make([]float64, 256*256)
+  noisey.GetGrid2D(&fbm, 0.0, 0.0, 1.0/64.0, 1.0/64.0, 256, 256, values)
+
+GetBatch2D() and GetGrid2D() fall back to calling Get2D() for each value if the
+source doesn't implement NoiseyBatch2D, so any source can be passed to them.
+Builder2D uses GetGrid2D() automatically.
+
+What a batch saves depends on the module:
+
+  * PerlinGenerator looks up the gradients of a lattice cell's corners once
+    and reuses them for the following values in the same cell. On a fine grid,
+    like an image spanning a few units of noise, most values share a cell
+    with their neighbour; compare BenchmarkFBM2DBuilderImage with
+    BenchmarkFBM2DBuilderImageBatch. Values that each land in a new cell cost
+    about the same as calling Get2D().
+  * FBMGenerator2D/3D and Select2D/3D sample their sources a whole batch at a
+    time and take their working slices from a pool, so repeated builds don't
+    allocate them again.
+  * OpenSimplexGenerator and Scale2D only save the interface call per value.
+
+The grid methods step the coordinates by repeated addition, the same way
+Builder2D.Build() always has, so batched values match the values that calling
+Get2D() on the same coordinates would produce.
+
+*/
+
+import (
+	"math"
+	"sync"
+)
+
+// NoiseyBatch2D is an interface for sources that can produce many 2D noise values at once.
+type NoiseyBatch2D interface {
+	// GetBatch2D fills out[i] with the noise at (xs[i], ys[i]) for every
+	// element of out; xs and ys must be at least as long as out.
+	GetBatch2D(xs, ys []float64, out []float64)
+
+	// GetGrid2D fills out with a width x height grid of noise values starting
+	// at (minX, minY) and stepping by dx and dy. Rows are stored one after another.
+	GetGrid2D(minX, minY, dx, dy float64, width, height int, out []float64)
+}
+
+// NoiseyBatch3D is an interface for sources that can produce many 3D noise values at once.
+type NoiseyBatch3D interface {
+	// GetBatch3D fills out[i] with the noise at (xs[i], ys[i], zs[i]) for every
+	// element of out; xs, ys and zs must be at least as long as out.
+	GetBatch3D(xs, ys, zs []float64, out []float64)
+
+	// GetGrid3D fills out with a width x height x depth grid of noise values
+	// starting at (minX, minY, minZ) and stepping by dx, dy and dz. The grid is
+	// stored as depth slices of height rows of width values.
+	GetGrid3D(minX, minY, minZ, dx, dy, dz float64, width, height, depth int, out []float64)
+}
+
+// GetBatch2D fills out with the noise from src at the coordinates in xs and ys,
+// using the NoiseyBatch2D interface if src implements it.
+func GetBatch2D(src NoiseyGet2D, xs, ys []float64, out []float64) {
+	if batcher, ok := src.(NoiseyBatch2D); ok {
+		batcher.GetBatch2D(xs, ys, out)
+		return
+	}
+	for i := range out {
+		out[i] = src.Get2D(xs[i], ys[i])
+	}
+}
+
+// GetGrid2D fills out with a grid of noise from src, using the NoiseyBatch2D
+// interface if src implements it. See NoiseyBatch2D.GetGrid2D().
+func GetGrid2D(src NoiseyGet2D, minX, minY, dx, dy float64, width, height int, out []float64) {
+	if batcher, ok := src.(NoiseyBatch2D); ok {
+		batcher.GetGrid2D(minX, minY, dx, dy, width, height, out)
+		return
+	}
+	y := minY
+	for j := 0; j < height; j++ {
+		x := minX
+		row := out[j*width : (j+1)*width]
+		for i := range row {
+			row[i] = src.Get2D(x, y)
+			x += dx
+		}
+		y += dy
+	}
+}
+
+// GetBatch3D fills out with the noise from src at the coordinates in xs, ys and zs,
+// using the NoiseyBatch3D interface if src implements it.
+func GetBatch3D(src NoiseyGet3D, xs, ys, zs []float64, out []float64) {
+	if batcher, ok := src.(NoiseyBatch3D); ok {
+		batcher.GetBatch3D(xs, ys, zs, out)
+		return
+	}
+	for i := range out {
+		out[i] = src.Get3D(xs[i], ys[i], zs[i])
+	}
+}
+
+// GetGrid3D fills out with a grid of noise from src, using the NoiseyBatch3D
+// interface if src implements it. See NoiseyBatch3D.GetGrid3D().
+func GetGrid3D(src NoiseyGet3D, minX, minY, minZ, dx, dy, dz float64, width, height, depth int, out []float64) {
+	if batcher, ok := src.(NoiseyBatch3D); ok {
+		batcher.GetGrid3D(minX, minY, minZ, dx, dy, dz, width, height, depth, out)
+		return
+	}
+	xs, ys, zs := gridCoords3D(minX, minY, minZ, dx, dy, dz, width, height, depth)
+	for i := range out[:len(xs)] {
+		out[i] = src.Get3D(xs[i], ys[i], zs[i])
+	}
+	putScratch(xs, ys, zs)
+}
+
+// batchScratch recycles the scratch slices of the batch methods so that
+// building the same map again doesn't allocate them on every call. They're
+// pooled instead of kept on the modules so that the modules stay safe to
+// share between the goroutines of BuildParallel().
+var batchScratch sync.Pool
+
+// getScratch returns a float64 slice of length n from batchScratch. The
+// values are left over from its last use.
+func getScratch(n int) []float64 {
+	if p, ok := batchScratch.Get().(*[]float64); ok && cap(*p) >= n {
+		return (*p)[:n]
+	}
+	return make([]float64, n)
+}
+
+// putScratch returns slices from getScratch() to batchScratch.
+func putScratch(slices ...[]float64) {
+	for i := range slices {
+		batchScratch.Put(&slices[i])
+	}
+}
+
+// indexScratch recycles the index slices of the batch methods like batchScratch.
+var indexScratch sync.Pool
+
+// getIndexScratch returns an int slice of length n from indexScratch.
+func getIndexScratch(n int) []int {
+	if p, ok := indexScratch.Get().(*[]int); ok && cap(*p) >= n {
+		return (*p)[:n]
+	}
+	return make([]int, n)
+}
+
+// putIndexScratch returns slices from getIndexScratch() to indexScratch.
+func putIndexScratch(slices ...[]int) {
+	for i := range slices {
+		indexScratch.Put(&slices[i])
+	}
+}
+
+// gridCoords2D expands a grid into coordinate slices for GetBatch2D(). The
+// slices come from getScratch().
+func gridCoords2D(minX, minY, dx, dy float64, width, height int) (xs, ys []float64) {
+	xs = getScratch(width * height)
+	ys = getScratch(width * height)
+	y := minY
+	for j := 0; j < height; j++ {
+		x := minX
+		for i := 0; i < width; i++ {
+			xs[j*width+i] = x
+			ys[j*width+i] = y
+			x += dx
+		}
+		y += dy
+	}
+	return
+}
+
+// gridCoords3D expands a grid into coordinate slices for GetBatch3D(). The
+// slices come from getScratch().
+func gridCoords3D(minX, minY, minZ, dx, dy, dz float64, width, height, depth int) (xs, ys, zs []float64) {
+	count := width * height * depth
+	xs = getScratch(count)
+	ys = getScratch(count)
+	zs = getScratch(count)
+	index := 0
+	z := minZ
+	for k := 0; k < depth; k++ {
+		y := minY
+		for j := 0; j < height; j++ {
+			x := minX
+			for i := 0; i < width; i++ {
+				xs[index] = x
+				ys[index] = y
+				zs[index] = z
+				index++
+				x += dx
+			}
+			y += dy
+		}
+		z += dz
+	}
+	return
+}
+
+/* ------------------------------------------------------------------------- */
+
+// GetBatch2D fills out with the noise at the coordinates in xs and ys.
+// Consecutive coordinates in the same lattice cell, like the neighbouring
+// values of a fine grid, reuse the gradients of the cell's corners instead of
+// looking them up again; coordinates that each land in a new cell cost the
+// same as calling Get2D().
+func (pg *PerlinGenerator) GetBatch2D(xs, ys []float64, out []float64) {
+	var cell Vec2i
+	var g [4]Vec2f
+	seen, cached := false, false
+	for i := range out {
+		fx, fy := math.Floor(xs[i]), math.Floor(ys[i])
+		frac0 := Vec2f{xs[i] - fx, ys[i] - fy}
+		whole := Vec2i{int(fx), int(fy)}
+
+		// the first value in a cell is calculated like Get2D() does, which
+		// in the attenuated mode skips the corners too far away to contribute;
+		// the gradients are only worth caching once a second value needs them
+		if !seen || whole != cell {
+			cell, seen, cached = whole, true, false
+			out[i] = pg.getLattice2D(whole, frac0)
+			continue
+		}
+		if !cached {
+			pg.cellGradients2(cell, &g)
+			cached = true
+		}
+
+		// the same math as getLattice2D() and getInterpolated2D() so that the
+		// values match Get2D() exactly
+		frac1 := Vec2f{frac0.X - 1, frac0.Y - 1}
+		if pg.Quality == QualityAttenuated {
+			f00 := attenuatedGradient2(frac0, g[0])
+			f10 := attenuatedGradient2(Vec2f{frac1.X, frac0.Y}, g[1])
+			f01 := attenuatedGradient2(Vec2f{frac0.X, frac1.Y}, g[2])
+			f11 := attenuatedGradient2(frac1, g[3])
+			out[i] = (f00 + f10 + f01 + f11 + 0.053179) * 1.056165
+			continue
+		}
+		f00 := vec2fDot(frac0, g[0])
+		f10 := vec2fDot(Vec2f{frac1.X, frac0.Y}, g[1])
+		f01 := vec2fDot(Vec2f{frac0.X, frac1.Y}, g[2])
+		f11 := vec2fDot(frac1, g[3])
+		sx := pg.Quality.sCurve(frac0.X)
+		sy := pg.Quality.sCurve(frac0.Y)
+		out[i] = lerp(lerp(f00, f10, sx), lerp(f01, f11, sx), sy)
+	}
+}
+
+// GetGrid2D fills out with a grid of noise values. See NoiseyBatch2D.
+func (pg *PerlinGenerator) GetGrid2D(minX, minY, dx, dy float64, width, height int, out []float64) {
+	xs, ys := gridRow2D(minX, dx, width)
+	y := minY
+	for j := 0; j < height; j++ {
+		for i := range ys {
+			ys[i] = y
+		}
+		pg.GetBatch2D(xs, ys, out[j*width:(j+1)*width])
+		y += dy
+	}
+	putScratch(xs, ys)
+}
+
+// GetBatch3D fills out with the noise at the coordinates in xs, ys and zs,
+// reusing the gradients of the lattice cell like GetBatch2D().
+func (pg *PerlinGenerator) GetBatch3D(xs, ys, zs []float64, out []float64) {
+	var cell Vec3i
+	var g [8]Vec3f
+	seen, cached := false, false
+	for i := range out {
+		fx, fy, fz := math.Floor(xs[i]), math.Floor(ys[i]), math.Floor(zs[i])
+		frac0 := Vec3f{xs[i] - fx, ys[i] - fy, zs[i] - fz}
+		whole := Vec3i{int(fx), int(fy), int(fz)}
+		if !seen || whole != cell {
+			cell, seen, cached = whole, true, false
+			out[i] = pg.getLattice3D(whole, frac0)
+			continue
+		}
+		if !cached {
+			pg.cellGradients3(cell, &g)
+			cached = true
+		}
+
+		// the same math as getLattice3D() and getInterpolated3D()
+		frac1 := Vec3f{frac0.X - 1, frac0.Y - 1, frac0.Z - 1}
+		if pg.Quality != QualityAttenuated {
+			f000 := vec3fDot(frac0, g[0])
+			f100 := vec3fDot(Vec3f{frac1.X, frac0.Y, frac0.Z}, g[1])
+			f010 := vec3fDot(Vec3f{frac0.X, frac1.Y, frac0.Z}, g[2])
+			f110 := vec3fDot(Vec3f{frac1.X, frac1.Y, frac0.Z}, g[3])
+			f001 := vec3fDot(Vec3f{frac0.X, frac0.Y, frac1.Z}, g[4])
+			f101 := vec3fDot(Vec3f{frac1.X, frac0.Y, frac1.Z}, g[5])
+			f011 := vec3fDot(Vec3f{frac0.X, frac1.Y, frac1.Z}, g[6])
+			f111 := vec3fDot(frac1, g[7])
+			sx := pg.Quality.sCurve(frac0.X)
+			sy := pg.Quality.sCurve(frac0.Y)
+			sz := pg.Quality.sCurve(frac0.Z)
+			y0 := lerp(lerp(f000, f100, sx), lerp(f010, f110, sx), sy)
+			y1 := lerp(lerp(f001, f101, sx), lerp(f011, f111, sx), sy)
+			out[i] = lerp(y0, y1, sz)
+			continue
+		}
+		f000 := attenuatedGradient3(frac0, g[0])
+		f100 := attenuatedGradient3(Vec3f{frac1.X, frac0.Y, frac0.Z}, g[1])
+		f010 := attenuatedGradient3(Vec3f{frac0.X, frac1.Y, frac0.Z}, g[2])
+		f110 := attenuatedGradient3(Vec3f{frac1.X, frac1.Y, frac0.Z}, g[3])
+		f001 := attenuatedGradient3(Vec3f{frac0.X, frac0.Y, frac1.Z}, g[4])
+		f101 := attenuatedGradient3(Vec3f{frac1.X, frac0.Y, frac1.Z}, g[5])
+		f011 := attenuatedGradient3(Vec3f{frac0.X, frac1.Y, frac1.Z}, g[6])
+		f111 := attenuatedGradient3(frac1, g[7])
+		out[i] = (f000 + f100 + f010 + f110 + f001 + f101 + f011 + f111 + 0.053179) * 1.056165
+	}
+}
+
+// GetGrid3D fills out with a grid of noise values. See NoiseyBatch3D.
+func (pg *PerlinGenerator) GetGrid3D(minX, minY, minZ, dx, dy, dz float64, width, height, depth int, out []float64) {
+	xs, ys := gridRow2D(minX, dx, width)
+	zs := getScratch(width)
+	index := 0
+	z := minZ
+	for k := 0; k < depth; k++ {
+		y := minY
+		for j := 0; j < height; j++ {
+			for i := range ys {
+				ys[i] = y
+				zs[i] = z
+			}
+			pg.GetBatch3D(xs, ys, zs, out[index:index+width])
+			index += width
+			y += dy
+		}
+		z += dz
+	}
+	putScratch(xs, ys, zs)
+}
+
+// cellGradients2 looks up the gradients of the four corners of the lattice
+// cell whose lowest corner is whole, indexed by corner: bit 0 is X and bit 1
+// is Y. Without FullHash or a Period the corners share the permutation
+// lookups of their X coordinates.
+func (pg *PerlinGenerator) cellGradients2(whole Vec2i, g *[4]Vec2f) {
+	if pg.FullHash || pg.Period.X > 0 || pg.Period.Y > 0 {
+		for c := range g {
+			g[c] = pg.getGradient2(Vec2i{whole.X + c&1, whole.Y + c>>1})
+		}
+		return
+	}
+
+	perm := pg.Permutations
+	xv := [2]int{perm[whole.X&0xFF], perm[(whole.X+1)&0xFF]}
+	y := [2]int{whole.Y & 0xFF, (whole.Y + 1) & 0xFF}
+	for c := range g {
+		gradient := pg.RandomGradients[perm[xv[c&1]^y[c>>1]]%32]
+		g[c] = Vec2f{gradient.X, gradient.Y}
+	}
+}
+
+// cellGradients3 is the 3D version of cellGradients2(); bit 2 of the corner is Z.
+func (pg *PerlinGenerator) cellGradients3(whole Vec3i, g *[8]Vec3f) {
+	if pg.FullHash || pg.Period.X > 0 || pg.Period.Y > 0 || pg.Period.Z > 0 {
+		for c := range g {
+			g[c] = pg.getGradient3(Vec3i{whole.X + c&1, whole.Y + (c>>1)&1, whole.Z + c>>2})
+		}
+		return
+	}
+
+	perm := pg.Permutations
+	xv := [2]int{perm[whole.X&0xFF], perm[(whole.X+1)&0xFF]}
+	y := [2]int{whole.Y & 0xFF, (whole.Y + 1) & 0xFF}
+	z := [2]int{whole.Z & 0xFF, (whole.Z + 1) & 0xFF}
+	for c := range g {
+		yv := perm[xv[c&1]^y[(c>>1)&1]]
+		gradient := pg.RandomGradients[perm[yv^z[c>>2]]%32]
+		g[c] = Vec3f{gradient.X, gradient.Y, gradient.Z}
+	}
+}
+
+// attenuatedGradient2 returns the contribution of a lattice corner with the
+// gradient g at the offset frac to the attenuated perlin noise, like the
+// gradient2 function in getLattice2D().
+func attenuatedGradient2(frac Vec2f, g Vec2f) float64 {
+	attn := 1.0 - vec2fDot(frac, frac)
+	if attn > 0.0 {
+		return (attn * attn) * vec2fDot(frac, g)
+	}
+	return 0.0
+}
+
+// attenuatedGradient3 is the 3D version of attenuatedGradient2().
+func attenuatedGradient3(frac Vec3f, g Vec3f) float64 {
+	attn := 1.0 - vec3fDot(frac, frac)
+	if attn > 0.0 {
+		return (attn * attn) * vec3fDot(frac, g)
+	}
+	return 0.0
+}
+
+// gridRow2D returns the X coordinates of a grid row, stepped by repeated
+// addition like GetGrid2D(), and a slice of the same length for the Y ones.
+// The slices come from getScratch().
+func gridRow2D(minX, dx float64, width int) (xs, ys []float64) {
+	xs = getScratch(width)
+	ys = getScratch(width)
+	x := minX
+	for i := range xs {
+		xs[i] = x
+		x += dx
+	}
+	return
+}
+
+// GetBatch2D fills out with the noise at the coordinates in xs and ys.
+func (osg *OpenSimplexGenerator) GetBatch2D(xs, ys []float64, out []float64) {
+	for i := range out {
+		out[i] = osg.Get2D(xs[i], ys[i])
+	}
+}
+
+// GetGrid2D fills out with a grid of noise values. See NoiseyBatch2D.
+func (osg *OpenSimplexGenerator) GetGrid2D(minX, minY, dx, dy float64, width, height int, out []float64) {
+	y := minY
+	for j := 0; j < height; j++ {
+		x := minX
+		row := out[j*width : (j+1)*width]
+		for i := range row {
+			row[i] = osg.Get2D(x, y)
+			x += dx
+		}
+		y += dy
+	}
+}
+
+// GetBatch3D fills out with the noise at the coordinates in xs, ys and zs.
+func (osg *OpenSimplexGenerator) GetBatch3D(xs, ys, zs []float64, out []float64) {
+	for i := range out {
+		out[i] = osg.Get3D(xs[i], ys[i], zs[i])
+	}
+}
+
+// GetGrid3D fills out with a grid of noise values. See NoiseyBatch3D.
+func (osg *OpenSimplexGenerator) GetGrid3D(minX, minY, minZ, dx, dy, dz float64, width, height, depth int, out []float64) {
+	index := 0
+	z := minZ
+	for k := 0; k < depth; k++ {
+		y := minY
+		for j := 0; j < height; j++ {
+			x := minX
+			for i := 0; i < width; i++ {
+				out[index] = osg.Get3D(x, y, z)
+				index++
+				x += dx
+			}
+			y += dy
+		}
+		z += dz
+	}
+}
+
+/* ------------------------------------------------------------------------- */
+
+// GetBatch2D calculates the fBm values for all of the coordinates, sampling
+// NoiseMaker one whole octave at a time.
+func (fbm *FBMGenerator2D) GetBatch2D(xs, ys []float64, out []float64) {
+	count := len(out)
+	x := getScratch(count)
+	y := getScratch(count)
+	signal := getScratch(count)
+	defer putScratch(x, y, signal)
+	for i := range out {
+		x[i] = xs[i] * fbm.Frequency
+		y[i] = ys[i] * fbm.Frequency
+		out[i] = 0.0
+	}
+
+	curPersistence := 1.0
+	for o := 0; o < fbm.Octaves; o++ {
+		GetBatch2D(fbm.NoiseMaker, x, y, signal)
+		for i := range out {
+			out[i] += signal[i] * curPersistence
+			x[i] *= fbm.Lacunarity
+			y[i] *= fbm.Lacunarity
+		}
+		curPersistence *= fbm.Persistence
+	}
+}
+
+// GetGrid2D fills out with a grid of fBm values. See NoiseyBatch2D.
+func (fbm *FBMGenerator2D) GetGrid2D(minX, minY, dx, dy float64, width, height int, out []float64) {
+	xs, ys := gridCoords2D(minX, minY, dx, dy, width, height)
+	fbm.GetBatch2D(xs, ys, out[:len(xs)])
+	putScratch(xs, ys)
+}
+
+// GetBatch3D calculates the fBm values for all of the coordinates, sampling
+// NoiseMaker one whole octave at a time.
+func (fbm *FBMGenerator3D) GetBatch3D(xs, ys, zs []float64, out []float64) {
+	count := len(out)
+	x := getScratch(count)
+	y := getScratch(count)
+	z := getScratch(count)
+	signal := getScratch(count)
+	defer putScratch(x, y, z, signal)
+	for i := range out {
+		x[i] = xs[i] * fbm.Frequency
+		y[i] = ys[i] * fbm.Frequency
+		z[i] = zs[i] * fbm.Frequency
+		out[i] = 0.0
+	}
+
+	curPersistence := 1.0
+	for o := 0; o < fbm.Octaves; o++ {
+		GetBatch3D(fbm.NoiseMaker, x, y, z, signal)
+		for i := range out {
+			out[i] += signal[i] * curPersistence
+			x[i] *= fbm.Lacunarity
+			y[i] *= fbm.Lacunarity
+			z[i] *= fbm.Lacunarity
+		}
+		curPersistence *= fbm.Persistence
+	}
+}
+
+// GetGrid3D fills out with a grid of fBm values. See NoiseyBatch3D.
+func (fbm *FBMGenerator3D) GetGrid3D(minX, minY, minZ, dx, dy, dz float64, width, height, depth int, out []float64) {
+	xs, ys, zs := gridCoords3D(minX, minY, minZ, dx, dy, dz, width, height, depth)
+	fbm.GetBatch3D(xs, ys, zs, out[:len(xs)])
+	putScratch(xs, ys, zs)
+}
+
+/* ------------------------------------------------------------------------- */
+
+// the regions of the control value that selectRegion() can return
+const (
+	selectRegionA         = iota // only SourceA is used
+	selectRegionB                // only SourceB is used
+	selectRegionLowerEdge        // blend from SourceA to SourceB
+	selectRegionUpperEdge        // blend from SourceB to SourceA
+)
+
+// selectRegion returns which sources a select module needs for the control
+// value and the blend factor to use in the edge regions. It mirrors the
+// branches of Select2D.Get2D().
+func selectRegion(control, lowerBound, upperBound, edgeFalloff float64) (int, float64) {
+	if edgeFalloff <= 0.0 {
+		if lowerBound < control && control < upperBound {
+			return selectRegionB, 0.0
+		}
+		return selectRegionA, 0.0
+	}
+
+	if control < lowerBound-edgeFalloff {
+		return selectRegionA, 0.0
+	}
+	if control < lowerBound+edgeFalloff {
+		lower := lowerBound - edgeFalloff
+		upper := lowerBound + edgeFalloff
+		return selectRegionLowerEdge, calcCubicSCurve((control - lower) / (upper - lower))
+	}
+	if control < upperBound-edgeFalloff {
+		return selectRegionB, 0.0
+	}
+	if control < upperBound+edgeFalloff {
+		lower := upperBound - edgeFalloff
+		upper := upperBound + edgeFalloff
+		return selectRegionUpperEdge, calcCubicSCurve((control - lower) / (upper - lower))
+	}
+	return selectRegionA, 0.0
+}
+
+// selectBatch holds the working data for the batched select modules: the
+// region and blend factor of each value and the indexes that need each source.
+// The slices come from the scratch pools and go back with release().
+type selectBatch struct {
+	regions []int
+	blends  []float64
+	needA   []int
+	needB   []int
+}
+
+// newSelectBatch classifies every control value.
+func newSelectBatch(control []float64, lowerBound, upperBound, edgeFalloff float64) (sb selectBatch) {
+	sb.regions = getIndexScratch(len(control))
+	sb.blends = getScratch(len(control))
+	sb.needA = getIndexScratch(len(control))[:0]
+	sb.needB = getIndexScratch(len(control))[:0]
+	for i, c := range control {
+		region, blend := selectRegion(c, lowerBound, upperBound, edgeFalloff)
+		sb.regions[i] = region
+		sb.blends[i] = blend
+		if region != selectRegionB {
+			sb.needA = append(sb.needA, i)
+		}
+		if region != selectRegionA {
+			sb.needB = append(sb.needB, i)
+		}
+	}
+	return
+}
+
+// release returns the slices of the select batch to the scratch pools.
+func (sb *selectBatch) release() {
+	putIndexScratch(sb.regions, sb.needA, sb.needB)
+	putScratch(sb.blends)
+}
+
+// gather copies the values of src at the indexes into a slice from getScratch().
+func gather(src []float64, indexes []int) []float64 {
+	dst := getScratch(len(indexes))
+	for i, index := range indexes {
+		dst[i] = src[index]
+	}
+	return dst
+}
+
+// combine writes the selected or blended values into out; a and b hold the
+// source values for the indexes in needA and needB. The values of SourceA are
+// stored in out first, and then the values of SourceB replace them or get
+// blended with them.
+func (sb *selectBatch) combine(a, b []float64, out []float64) {
+	for i, index := range sb.needA {
+		out[index] = a[i]
+	}
+	for i, index := range sb.needB {
+		switch sb.regions[index] {
+		case selectRegionB:
+			out[index] = b[i]
+		case selectRegionLowerEdge:
+			out[index] = lerp(out[index], b[i], sb.blends[index])
+		case selectRegionUpperEdge:
+			out[index] = lerp(b[i], out[index], sb.blends[index])
+		}
+	}
+}
+
+// GetBatch2D calculates the selected noise values for all of the coordinates.
+// SourceA and SourceB are only sampled at the coordinates that need them.
+func (selector *Select2D) GetBatch2D(xs, ys []float64, out []float64) {
+	control := getScratch(len(out))
+	GetBatch2D(selector.Control, xs, ys, control)
+	sb := newSelectBatch(control, selector.LowerBound, selector.UpperBound, selector.EdgeFalloff)
+	defer sb.release()
+
+	a := getScratch(len(sb.needA))
+	ax, ay := gather(xs, sb.needA), gather(ys, sb.needA)
+	GetBatch2D(selector.SourceA, ax, ay, a)
+	b := getScratch(len(sb.needB))
+	bx, by := gather(xs, sb.needB), gather(ys, sb.needB)
+	GetBatch2D(selector.SourceB, bx, by, b)
+	sb.combine(a, b, out)
+	putScratch(control, a, ax, ay, b, bx, by)
+}
+
+// GetGrid2D fills out with a grid of selected noise values. See NoiseyBatch2D.
+func (selector *Select2D) GetGrid2D(minX, minY, dx, dy float64, width, height int, out []float64) {
+	xs, ys := gridCoords2D(minX, minY, dx, dy, width, height)
+	selector.GetBatch2D(xs, ys, out[:len(xs)])
+	putScratch(xs, ys)
+}
+
+// GetBatch3D calculates the selected noise values for all of the coordinates.
+// SourceA and SourceB are only sampled at the coordinates that need them.
+func (selector *Select3D) GetBatch3D(xs, ys, zs []float64, out []float64) {
+	control := getScratch(len(out))
+	GetBatch3D(selector.Control, xs, ys, zs, control)
+	sb := newSelectBatch(control, selector.LowerBound, selector.UpperBound, selector.EdgeFalloff)
+	defer sb.release()
+
+	a := getScratch(len(sb.needA))
+	ax, ay, az := gather(xs, sb.needA), gather(ys, sb.needA), gather(zs, sb.needA)
+	GetBatch3D(selector.SourceA, ax, ay, az, a)
+	b := getScratch(len(sb.needB))
+	bx, by, bz := gather(xs, sb.needB), gather(ys, sb.needB), gather(zs, sb.needB)
+	GetBatch3D(selector.SourceB, bx, by, bz, b)
+	sb.combine(a, b, out)
+	putScratch(control, a, ax, ay, az, b, bx, by, bz)
+}
+
+// GetGrid3D fills out with a grid of selected noise values. See NoiseyBatch3D.
+func (selector *Select3D) GetGrid3D(minX, minY, minZ, dx, dy, dz float64, width, height, depth int, out []float64) {
+	xs, ys, zs := gridCoords3D(minX, minY, minZ, dx, dy, dz, width, height, depth)
+	selector.GetBatch3D(xs, ys, zs, out[:len(xs)])
+	putScratch(xs, ys, zs)
+}
+
+/* ------------------------------------------------------------------------- */
+
+// GetBatch2D calculates the scaled noise values for all of the coordinates.
+func (scales *Scale2D) GetBatch2D(xs, ys []float64, out []float64) {
+	GetBatch2D(scales.Source, xs, ys, out)
+	scales.apply(out)
+}
+
+// GetGrid2D fills out with a grid of scaled noise values. See NoiseyBatch2D.
+func (scales *Scale2D) GetGrid2D(minX, minY, dx, dy float64, width, height int, out []float64) {
+	GetGrid2D(scales.Source, minX, minY, dx, dy, width, height, out)
+	scales.apply(out[:width*height])
+}
+
+// apply scales, biases and clamps the values in place like Scale2D.Get2D().
+func (scales *Scale2D) apply(values []float64) {
+	for i, v := range values {
+		v *= scales.Scale
+		v += scales.Bias
+		v = math.Max(scales.Min, v)
+		v = math.Min(scales.Max, v)
+		values[i] = v
+	}
+}
@@ -0,0 +1,125 @@
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+import (
+	"testing"
+)
+
+// getOnly hides the batch methods of a source so that the fallback is used.
+type getOnly struct {
+	src NoiseyGet2D
+}
+
+func (g getOnly) Get2D(x, y float64) float64 {
+	return g.src.Get2D(x, y)
+}
+
+func TestBatchMatchesGet2D(t *testing.T) {
+	perlin := NewPerlinGeneratorSeed(1)
+	simplex := NewOpenSimplexGeneratorSeed(2)
+	fbm := NewFBMGenerator2D(&perlin, 4, 0.5, 2.0, 1.3)
+	sel := NewSelect2D(&perlin, &fbm, &simplex, -0.1, 0.2, 0.1)
+	scale := NewScale2D(&sel, 2.0, 0.5, -1.0, 1.0)
+	norm := NewNormalize2D(&fbm, -1.2, 1.2, 0.0, 1.0)
+
+	sources := map[string]NoiseyGet2D{
+		"perlin":      &perlin,
+		"opensimplex": &simplex,
+		"fBm":         &fbm,
+		"select":      &sel,
+		"scale":       &scale,
+		"normalize":   &norm,
+		"fallback":    getOnly{&scale},
+	}
+
+	const width, height = 37, 23
+	for name, src := range sources {
+		grid := make([]float64, width*height)
+		GetGrid2D(src, -3.1, 1.7, 0.13, 0.21, width, height, grid)
+		xs, ys := gridCoords2D(-3.1, 1.7, 0.13, 0.21, width, height)
+		batch := make([]float64, width*height)
+		GetBatch2D(src, xs, ys, batch)
+
+		for i := range grid {
+			expected := src.Get2D(xs[i], ys[i])
+			if grid[i] != expected || batch[i] != expected {
+				t.Errorf("%s: value %d was %v (grid) and %v (batch), expected %v", name, i, grid[i], batch[i], expected)
+				break
+			}
+		}
+	}
+}
+
+func TestBatchMatchesGet3D(t *testing.T) {
+	perlin := NewPerlinGeneratorSeed(1)
+	simplex := NewOpenSimplexGeneratorSeed(2)
+	fbm := NewFBMGenerator3D(&simplex, 3, 0.5, 2.0, 0.7)
+	sel := NewSelect3D(&perlin, &fbm, &simplex, -0.1, 0.2, 0.1)
+	norm := NewNormalize3D(&fbm, -1.2, 1.2, 0.0, 1.0)
+
+	sources := map[string]NoiseyGet3D{
+		"perlin":      &perlin,
+		"opensimplex": &simplex,
+		"fBm":         &fbm,
+		"select":      &sel,
+		"normalize":   &norm,
+	}
+
+	const width, height, depth = 7, 5, 3
+	for name, src := range sources {
+		grid := make([]float64, width*height*depth)
+		GetGrid3D(src, -1.1, 0.7, 2.3, 0.31, 0.27, 0.45, width, height, depth, grid)
+		xs, ys, zs := gridCoords3D(-1.1, 0.7, 2.3, 0.31, 0.27, 0.45, width, height, depth)
+		batch := make([]float64, len(grid))
+		GetBatch3D(src, xs, ys, zs, batch)
+
+		for i := range grid {
+			expected := src.Get3D(xs[i], ys[i], zs[i])
+			if grid[i] != expected || batch[i] != expected {
+				t.Errorf("%s: value %d was %v (grid) and %v (batch), expected %v", name, i, grid[i], batch[i], expected)
+				break
+			}
+		}
+	}
+}
+
+func TestPerlinBatchCellReuse(t *testing.T) {
+	// a fine grid puts many neighbouring values in the same lattice cell,
+	// which reuses the cached gradients in every mode of the generator
+	variants := map[string]func(pg *PerlinGenerator){
+		"attenuated": func(pg *PerlinGenerator) {},
+		"fast":       func(pg *PerlinGenerator) { pg.Quality = QualityFast },
+		"standard":   func(pg *PerlinGenerator) { pg.Quality = QualityStandard },
+		"best":       func(pg *PerlinGenerator) { pg.Quality = QualityBest },
+		"fullhash":   func(pg *PerlinGenerator) { pg.FullHash = true },
+		"periodic":   func(pg *PerlinGenerator) { pg.Period = Vec3i{2, 3, 2} },
+	}
+
+	const width, height, depth = 40, 6, 4
+	for name, setup := range variants {
+		perlin := NewPerlinGeneratorSeed(4)
+		setup(&perlin)
+
+		grid := make([]float64, width*height)
+		perlin.GetGrid2D(-1.3, -0.6, 0.07, 0.3, width, height, grid)
+		xs, ys := gridCoords2D(-1.3, -0.6, 0.07, 0.3, width, height)
+		for i := range grid {
+			if expected := perlin.Get2D(xs[i], ys[i]); grid[i] != expected {
+				t.Errorf("%s: 2D value %d was %v, expected %v", name, i, grid[i], expected)
+				break
+			}
+		}
+
+		grid = make([]float64, width*height*depth)
+		perlin.GetGrid3D(-1.3, -0.6, 0.2, 0.07, 0.3, 0.4, width, height, depth, grid)
+		xs, ys, zs := gridCoords3D(-1.3, -0.6, 0.2, 0.07, 0.3, 0.4, width, height, depth)
+		for i := range grid {
+			if expected := perlin.Get3D(xs[i], ys[i], zs[i]); grid[i] != expected {
+				t.Errorf("%s: 3D value %d was %v, expected %v", name, i, grid[i], expected)
+				break
+			}
+		}
+	}
+}
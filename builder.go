@@ -5,7 +5,10 @@ See the LICENSE file for more details. */
 
 /* This module contains code to easily build 'maps' of random noise. */
 
-import "math"
+import (
+	"math"
+	"sync"
+)
 
 // Builder2DBounds is a simple rectangle type.
 type Builder2DBounds struct {
@@ -32,30 +35,63 @@ func NewBuilder2D(s NoiseyGet2D, width int, height int) (b Builder2D) {
 
 // Build gets noise from Source for each spot in the data array. These steps
 // are real numbers so that Bounds does not have to match Width/Height.
+// If Source implements NoiseyBatch2D the whole grid is sampled in one call.
 func (b *Builder2D) Build() {
 	// setup the initial parameters controlling how the noise is sampled
 	xExtent := b.Bounds.MaxX - b.Bounds.MinX
 	yExtent := b.Bounds.MaxY - b.Bounds.MinY
 	xDelta := xExtent / float64(b.Width)
 	yDelta := yExtent / float64(b.Height)
-	xCur := b.Bounds.MinX
-	yCur := b.Bounds.MinY
 
-	for y := 0; y < b.Height; y++ {
-		xCur = b.Bounds.MinX
-		for x := 0; x < b.Width; x++ {
-			value := b.Source.Get2D(xCur, yCur)
-			b.Values[(y*b.Width)+x] = value
-			xCur += xDelta
-		}
+	GetGrid2D(b.Source, b.Bounds.MinX, b.Bounds.MinY, xDelta, yDelta, b.Width, b.Height, b.Values)
+}
+
+// BuildParallel does the same as Build() but splits the rows between the
+// number of workers, each running in its own goroutine. Source must be safe for
+// concurrent use, which all of the modules are except for Cache2D and Cache3D.
+// The Values are identical to the ones Build() calculates.
+func (b *Builder2D) BuildParallel(workers int) {
+	if workers < 2 || b.Height < 2 {
+		b.Build()
+		return
+	}
+	if workers > b.Height {
+		workers = b.Height
+	}
+
+	// setup the initial parameters controlling how the noise is sampled
+	xExtent := b.Bounds.MaxX - b.Bounds.MinX
+	yExtent := b.Bounds.MaxY - b.Bounds.MinY
+	xDelta := xExtent / float64(b.Width)
+	yDelta := yExtent / float64(b.Height)
+
+	// step the y coordinate the same way the grid sampling does so that every
+	// band starts on exactly the same coordinate as it would with Build()
+	rowY := make([]float64, b.Height)
+	yCur := b.Bounds.MinY
+	for y := range rowY {
+		rowY[y] = yCur
 		yCur += yDelta
 	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		first := w * b.Height / workers
+		last := (w + 1) * b.Height / workers
+		wg.Add(1)
+		go func(first, last int) {
+			defer wg.Done()
+			rows := b.Values[first*b.Width : last*b.Width]
+			GetGrid2D(b.Source, b.Bounds.MinX, rowY[first], xDelta, yDelta, b.Width, last-first, rows)
+		}(first, last)
+	}
+	wg.Wait()
 }
 
 // GetMinMax returns the lowest and the highest Values
 func (b *Builder2D) GetMinMax() (min float64, max float64) {
 	var low float64 = math.MaxFloat64
-	var high float64 = math.SmallestNonzeroFloat64
+	var high float64 = -math.MaxFloat64
 
 	totalIndex := b.Width * b.Height
 	for i := 0; i < totalIndex; i++ {
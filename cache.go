@@ -0,0 +1,282 @@
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+/*
+
+This module contains the caching modules.
+
+Cache2D and Cache3D remember the last value sampled from their source. A
+generator that is used by several modules in a graph gets sampled at the same
+coordinate by each of them, so putting a cache in front of it means it only
+gets calculated once per coordinate:
+
+  landcontrol := noisey.NewFBMGenerator2D(&perlin, 2, 0.5, 2.0, 1.0)
+  cached := noisey.NewCache2D(&landcontrol)
+  sel := noisey.NewSelect2D(&hifreq, &flatter, &cached, 0.0, 100.0, 0.2)
+
+NoiseJSON does this automatically when AutoCache is set.
+
+TileCache2D keeps the values of a source on a regular lattice in square tiles
+with a least recently used eviction policy. It is meant for builders that sample
+overlapping regions over and over again, like a scrolling map:
+
+  tiles := noisey.NewTileCache2D(&fbm, 1.0/64.0, 64, 256)
+  builder := noisey.NewBuilder2D(tiles, 256, 256)
+  builder.Bounds = noisey.Builder2DBounds{scrollX, scrollY, scrollX + 4.0, scrollY + 4.0}
+  builder.Build()
+
+Only coordinates lying exactly on the lattice (multiples of Step) are cached and
+the rest are passed to the source. Builders hit the cache when their sample
+spacing equals Step and their bounds start on the lattice, which is easiest to
+get with a power of two Step.
+
+Cache2D and Cache3D are not safe for concurrent use; TileCache2D is.
+
+*/
+
+import (
+	"container/list"
+	"math"
+	"sync"
+)
+
+// Cache2D is a module that remembers the last value sampled from Source.
+type Cache2D struct {
+	// the noise that the cache module uses
+	Source NoiseyGet2D
+
+	// the last sample
+	valid  bool
+	x, y   float64
+	cached float64
+}
+
+// NewCache2D creates a new cache 2d module.
+func NewCache2D(src NoiseyGet2D) (cache Cache2D) {
+	cache.Source = src
+	return
+}
+
+// Get2D returns the noise value from Source, only sampling it if the
+// coordinate differs from the previous call.
+func (cache *Cache2D) Get2D(x float64, y float64) float64 {
+	if !cache.valid || cache.x != x || cache.y != y {
+		cache.cached = cache.Source.Get2D(x, y)
+		cache.x, cache.y = x, y
+		cache.valid = true
+	}
+	return cache.cached
+}
+
+// Reset forgets the last sample; call it after Source has been modified.
+func (cache *Cache2D) Reset() {
+	cache.valid = false
+}
+
+// GetBatch2D passes the batch on to Source; batches aren't cached.
+func (cache *Cache2D) GetBatch2D(xs, ys []float64, out []float64) {
+	GetBatch2D(cache.Source, xs, ys, out)
+}
+
+// GetGrid2D passes the grid on to Source; grids aren't cached.
+func (cache *Cache2D) GetGrid2D(minX, minY, dx, dy float64, width, height int, out []float64) {
+	GetGrid2D(cache.Source, minX, minY, dx, dy, width, height, out)
+}
+
+// Cache3D is a module that remembers the last value sampled from Source.
+type Cache3D struct {
+	// the noise that the cache module uses
+	Source NoiseyGet3D
+
+	// the last sample
+	valid   bool
+	x, y, z float64
+	cached  float64
+}
+
+// NewCache3D creates a new cache 3d module.
+func NewCache3D(src NoiseyGet3D) (cache Cache3D) {
+	cache.Source = src
+	return
+}
+
+// Get3D returns the noise value from Source, only sampling it if the
+// coordinate differs from the previous call.
+func (cache *Cache3D) Get3D(x float64, y float64, z float64) float64 {
+	if !cache.valid || cache.x != x || cache.y != y || cache.z != z {
+		cache.cached = cache.Source.Get3D(x, y, z)
+		cache.x, cache.y, cache.z = x, y, z
+		cache.valid = true
+	}
+	return cache.cached
+}
+
+// Reset forgets the last sample; call it after Source has been modified.
+func (cache *Cache3D) Reset() {
+	cache.valid = false
+}
+
+// GetBatch3D passes the batch on to Source; batches aren't cached.
+func (cache *Cache3D) GetBatch3D(xs, ys, zs []float64, out []float64) {
+	GetBatch3D(cache.Source, xs, ys, zs, out)
+}
+
+// GetGrid3D passes the grid on to Source; grids aren't cached.
+func (cache *Cache3D) GetGrid3D(minX, minY, minZ, dx, dy, dz float64, width, height, depth int, out []float64) {
+	GetGrid3D(cache.Source, minX, minY, minZ, dx, dy, dz, width, height, depth, out)
+}
+
+/* ------------------------------------------------------------------------- */
+
+// TileCache2D is a module that caches the values of Source on the lattice of
+// points that are multiples of Step, in tiles of TileSize x TileSize values.
+// At most Capacity tiles are kept; the least recently used one is dropped first.
+type TileCache2D struct {
+	// the noise that the cache module uses
+	Source NoiseyGet2D
+
+	// the distance between the lattice points that get cached
+	Step float64
+
+	// the number of lattice points along each side of a tile
+	TileSize int
+
+	// the maximum number of tiles to keep
+	Capacity int
+
+	mutex sync.Mutex
+	tiles map[Vec2i]*list.Element
+	lru   *list.List // of *cacheTile, most recently used first
+}
+
+// cacheTile holds the values of one tile of a TileCache2D.
+type cacheTile struct {
+	key    Vec2i
+	values []float64
+}
+
+// NewTileCache2D creates a new tile cache 2d module.
+func NewTileCache2D(src NoiseyGet2D, step float64, tileSize int, capacity int) *TileCache2D {
+	cache := new(TileCache2D)
+	cache.Source = src
+	cache.Step = step
+	cache.TileSize = tileSize
+	cache.Capacity = capacity
+	return cache
+}
+
+// Reset drops all of the cached tiles; call it after Source has been modified.
+func (cache *TileCache2D) Reset() {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.tiles = nil
+	cache.lru = nil
+}
+
+// Get2D returns the noise value from Source, using the cached tiles if the
+// coordinate lies on the lattice.
+func (cache *TileCache2D) Get2D(x float64, y float64) float64 {
+	ix, okx := cache.latticeIndex(x)
+	iy, oky := cache.latticeIndex(y)
+	if !okx || !oky {
+		return cache.Source.Get2D(x, y)
+	}
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	return cache.lookup(ix, iy)
+}
+
+// GetBatch2D fills out with the noise at the coordinates in xs and ys.
+func (cache *TileCache2D) GetBatch2D(xs, ys []float64, out []float64) {
+	for i := range out {
+		out[i] = cache.Get2D(xs[i], ys[i])
+	}
+}
+
+// GetGrid2D fills out with a grid of noise values. Grids that start on the
+// lattice and step by Step are copied out of the tiles directly, using the
+// values at the exact multiples of Step.
+func (cache *TileCache2D) GetGrid2D(minX, minY, dx, dy float64, width, height int, out []float64) {
+	ix0, okx := cache.latticeIndex(minX)
+	iy0, oky := cache.latticeIndex(minY)
+	if !okx || !oky || dx != cache.Step || dy != cache.Step {
+		GetGrid2D(cache.Source, minX, minY, dx, dy, width, height, out)
+		return
+	}
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	for j := 0; j < height; j++ {
+		for i := 0; i < width; i++ {
+			out[j*width+i] = cache.lookup(ix0+i, iy0+j)
+		}
+	}
+}
+
+// latticeIndex returns the lattice index of v and whether v lies exactly on the lattice.
+func (cache *TileCache2D) latticeIndex(v float64) (int, bool) {
+	if cache.Step <= 0.0 || cache.TileSize <= 0 {
+		return 0, false
+	}
+	i := math.Floor(v/cache.Step + 0.5)
+	if i*cache.Step != v || math.Abs(i) > float64(math.MaxInt32) {
+		return 0, false
+	}
+	return int(i), true
+}
+
+// lookup returns the value at the lattice point (ix, iy), building its tile
+// if needed. The mutex must be held.
+func (cache *TileCache2D) lookup(ix, iy int) float64 {
+	size := cache.TileSize
+	key := Vec2i{floorDiv(ix, size), floorDiv(iy, size)}
+	if cache.tiles == nil {
+		cache.tiles = make(map[Vec2i]*list.Element)
+		cache.lru = list.New()
+	}
+
+	var tile *cacheTile
+	if elem, ok := cache.tiles[key]; ok {
+		cache.lru.MoveToFront(elem)
+		tile = elem.Value.(*cacheTile)
+	} else {
+		tile = cache.buildTile(key)
+		cache.tiles[key] = cache.lru.PushFront(tile)
+		for cache.lru.Len() > cache.Capacity && cache.lru.Len() > 1 {
+			oldest := cache.lru.Back()
+			cache.lru.Remove(oldest)
+			delete(cache.tiles, oldest.Value.(*cacheTile).key)
+		}
+	}
+
+	return tile.values[(iy-key.Y*size)*size+(ix-key.X*size)]
+}
+
+// buildTile samples Source for all of the lattice points in the tile.
+func (cache *TileCache2D) buildTile(key Vec2i) *cacheTile {
+	size := cache.TileSize
+	xs := make([]float64, size*size)
+	ys := make([]float64, size*size)
+	for j := 0; j < size; j++ {
+		for i := 0; i < size; i++ {
+			xs[j*size+i] = float64(key.X*size+i) * cache.Step
+			ys[j*size+i] = float64(key.Y*size+j) * cache.Step
+		}
+	}
+
+	tile := &cacheTile{key: key, values: make([]float64, size*size)}
+	GetBatch2D(cache.Source, xs, ys, tile.values)
+	return tile
+}
+
+// floorDiv divides a by b rounding towards negative infinity.
+func floorDiv(a, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
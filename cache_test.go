@@ -0,0 +1,96 @@
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+import (
+	"testing"
+)
+
+// countingSource counts how many times it gets sampled.
+type countingSource struct {
+	src   NoiseyGet2D
+	count int
+}
+
+func (c *countingSource) Get2D(x, y float64) float64 {
+	c.count++
+	return c.src.Get2D(x, y)
+}
+
+func TestCache2D(t *testing.T) {
+	perlin := NewPerlinGeneratorSeed(1)
+	counter := countingSource{src: &perlin}
+	cache := NewCache2D(&counter)
+
+	v := cache.Get2D(1.5, 2.5)
+	if cache.Get2D(1.5, 2.5) != v || counter.count != 1 {
+		t.Errorf("Cache2D sampled its source %d times for the same coordinate", counter.count)
+	}
+	if cache.Get2D(1.75, 2.5) != perlin.Get2D(1.75, 2.5) || counter.count != 2 {
+		t.Errorf("Cache2D didn't sample its source for a new coordinate")
+	}
+}
+
+func TestTileCache2D(t *testing.T) {
+	perlin := NewPerlinGeneratorSeed(1)
+	counter := countingSource{src: &perlin}
+	tiles := NewTileCache2D(&counter, 0.25, 8, 4)
+
+	builder := NewBuilder2D(tiles, 16, 16)
+	builder.Bounds = Builder2DBounds{-2.0, -2.0, 2.0, 2.0}
+	builder.Build()
+	if counter.count != 4*8*8 {
+		t.Errorf("TileCache2D sampled %d values for 4 tiles", counter.count)
+	}
+
+	// building again hits the cache
+	builder.Build()
+	if counter.count != 4*8*8 {
+		t.Errorf("TileCache2D sampled its source again for cached tiles")
+	}
+
+	for y := 0; y < builder.Height; y++ {
+		for x := 0; x < builder.Width; x++ {
+			expected := perlin.Get2D(-2.0+float64(x)*0.25, -2.0+float64(y)*0.25)
+			if builder.Values[y*builder.Width+x] != expected {
+				t.Fatalf("TileCache2D value at %d,%d doesn't match the source", x, y)
+			}
+		}
+	}
+
+	// coordinates off the lattice go straight to the source
+	if tiles.Get2D(0.1, 0.2) != perlin.Get2D(0.1, 0.2) {
+		t.Errorf("TileCache2D value off the lattice doesn't match the source")
+	}
+}
+
+func TestNoiseJSONAutoCache(t *testing.T) {
+	cfg := NewNoiseJSON()
+	cfg.AutoCache = true
+	cfg.Seeds["Default"] = 1
+	cfg.Sources["perlin"] = SourceJSON{SourceType: "perlin", Seed: "Default"}
+	cfg.Generators = []GeneratorJSON{
+		{Name: "a", GeneratorType: "fBm2d", Sources: []string{"perlin"}, Octaves: 2, Persistence: 0.5, Lacunarity: 2.0, Frequency: 1.0},
+		{Name: "b", GeneratorType: "fBm2d", Sources: []string{"perlin"}, Octaves: 3, Persistence: 0.5, Lacunarity: 2.0, Frequency: 1.0},
+		{Name: "sel", GeneratorType: "select2d", Generators: []string{"a", "b", "a"}, LowerBound: 0.0, UpperBound: 1.0, EdgeFalloff: 0.1},
+	}
+	err := cfg.BuildSources(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cfg.BuildGenerators()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := cfg.builtSources["perlin"].(*Cache2D); !ok {
+		t.Errorf("the shared source wasn't cached")
+	}
+	if _, ok := cfg.GetGenerator("a").(*Cache2D); !ok {
+		t.Errorf("the shared generator wasn't cached")
+	}
+	if _, ok := cfg.GetGenerator("b").(*Cache2D); ok {
+		t.Errorf("a generator with one consumer was cached")
+	}
+}
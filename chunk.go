@@ -0,0 +1,167 @@
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+/*
+
+This module builds the noise of an open world in fixed size chunks at integer
+chunk coordinates, so that terrain can be streamed in around the player:
+
+  chunks := noisey.NewChunkBuilder(&fbm, 64, 1.0/64.0)
+  chunks.Border = 1
+  chunk := chunks.Build(cx, cy)
+  mesh := chunk.Mesh(1.0, 32.0, 0.0)
+
+A chunk has Size + 1 samples along each side so that it covers Size x Size
+cells, and its last row and column are the first ones of the next chunks.
+The coordinates of the samples are calculated from their integer position in
+the world times Step instead of being accumulated like Builder2D.Build() does,
+so the samples that neighbouring chunks share are exactly identical and the
+seams never show.
+
+With a Border, every chunk also gets that many samples more on each side,
+which lets Chunk.Mesh() calculate the normals along the edges from the
+neighbouring chunks' samples; the normals at the seams then match too.
+
+Many chunks can be built at once on several goroutines with BuildChunks(),
+which requires Source to be safe for concurrent use like BuildParallel().
+
+*/
+
+import (
+	"sync"
+)
+
+// ChunkBuilder builds the noise of chunks of a world from Source.
+type ChunkBuilder struct {
+	// the noise that the chunks are built from
+	Source NoiseyGet2D
+
+	// the number of cells along each side of a chunk
+	Size int
+
+	// the distance in noise coordinates between neighbouring samples
+	Step float64
+
+	// the number of extra samples around each chunk
+	Border int
+}
+
+// Chunk is the noise of one chunk. The samples are only read through Get(),
+// Interior() and Mesh(): building them again with Builder2D.Build() would
+// accumulate the coordinates and lose the exact seams. Use Interior() to get
+// a Builder2D for helpers like Image() and Stats().
+type Chunk struct {
+	// the samples of the chunk, including the border
+	samples Builder2D
+
+	// the chunk coordinates
+	X, Y int
+
+	// the number of cells along each side of the chunk and of extra samples around it
+	Size   int
+	Border int
+
+	// the distance in noise coordinates between neighbouring samples
+	Step float64
+}
+
+// NewChunkBuilder creates a new chunk builder for chunks of size x size cells
+// with step between the samples.
+func NewChunkBuilder(src NoiseyGet2D, size int, step float64) (cb ChunkBuilder) {
+	cb.Source = src
+	cb.Size = size
+	cb.Step = step
+	return
+}
+
+// Build samples the chunk at the chunk coordinates (cx, cy).
+func (cb *ChunkBuilder) Build(cx, cy int) Chunk {
+	side := cb.Size + 1 + 2*cb.Border
+	firstX := cx*cb.Size - cb.Border
+	firstY := cy*cb.Size - cb.Border
+
+	xs := make([]float64, side*side)
+	ys := make([]float64, side*side)
+	for j := 0; j < side; j++ {
+		for i := 0; i < side; i++ {
+			xs[j*side+i] = float64(firstX+i) * cb.Step
+			ys[j*side+i] = float64(firstY+j) * cb.Step
+		}
+	}
+
+	c := Chunk{X: cx, Y: cy, Size: cb.Size, Border: cb.Border, Step: cb.Step}
+	c.samples = NewBuilder2D(cb.Source, side, side)
+	c.samples.Bounds = c.bounds(firstX, firstY, side)
+	GetBatch2D(cb.Source, xs, ys, c.samples.Values)
+	return c
+}
+
+// BuildChunks builds the chunks at all of the chunk coordinates, splitting
+// them between the number of workers, each running in its own goroutine.
+// The chunks are returned in the same order as coords.
+func (cb *ChunkBuilder) BuildChunks(coords []Vec2i, workers int) []Chunk {
+	chunks := make([]Chunk, len(coords))
+	if workers < 1 {
+		workers = 1
+	}
+
+	var wg sync.WaitGroup
+	next := make(chan int)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				chunks[i] = cb.Build(coords[i].X, coords[i].Y)
+			}
+		}()
+	}
+	for i := range coords {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	return chunks
+}
+
+// Get returns the sample at (x, y) relative to the first corner of the chunk;
+// 0..Size is inside the chunk and the border reaches Border further out.
+func (c *Chunk) Get(x, y int) float64 {
+	return c.samples.Values[(y+c.Border)*c.samples.Width+x+c.Border]
+}
+
+// Interior returns the samples of the chunk without the border as a new
+// (Size + 1) x (Size + 1) Builder2D.
+func (c *Chunk) Interior() Builder2D {
+	side := c.Size + 1
+	b := NewBuilder2D(c.samples.Source, side, side)
+	b.Bounds = c.bounds(c.X*c.Size, c.Y*c.Size, side)
+	for y := 0; y < side; y++ {
+		for x := 0; x < side; x++ {
+			b.Values[y*side+x] = c.Get(x, y)
+		}
+	}
+	return b
+}
+
+// bounds returns the Builder2DBounds of side x side samples starting with the
+// sample at the world sample position (firstX, firstY).
+func (c *Chunk) bounds(firstX, firstY, side int) Builder2DBounds {
+	return Builder2DBounds{
+		MinX: float64(firstX) * c.Step,
+		MinY: float64(firstY) * c.Step,
+		MaxX: float64(firstX+side) * c.Step,
+		MaxY: float64(firstY+side) * c.Step,
+	}
+}
+
+// Mesh triangulates the chunk like Builder2D.Mesh() but leaves out the border,
+// using it for the normals along the edges, and places the chunk in the world
+// so that the meshes of neighbouring chunks join up.
+func (c *Chunk) Mesh(spacing float64, heightScale float64, tolerance float64) *Mesh {
+	origin := Vec2f{float64(c.X*c.Size) * spacing, float64(c.Y*c.Size) * spacing}
+	return meshHeightmap(c.samples.Values, c.Size+1, c.Size+1, c.Border, origin, spacing, heightScale, tolerance)
+}
@@ -0,0 +1,67 @@
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+import (
+	"testing"
+)
+
+func TestChunkSeams(t *testing.T) {
+	perlin := NewPerlinGeneratorSeed(1)
+	fbm := NewFBMGenerator2D(&perlin, 3, 0.5, 2.0, 1.0)
+	cb := NewChunkBuilder(&fbm, 16, 0.07)
+	cb.Border = 1
+
+	coords := []Vec2i{{-1, 0}, {0, 0}, {-1, 1}}
+	chunks := cb.BuildChunks(coords, 3)
+	left, right, below := chunks[0], chunks[1], chunks[2]
+	if left.X != -1 || right.X != 0 || below.Y != 1 || left.samples.Width != 19 || len(left.samples.Values) != 19*19 {
+		t.Fatalf("BuildChunks built the wrong chunks")
+	}
+
+	for i := -1; i <= 17; i++ {
+		if left.Get(16, i) != right.Get(0, i) || left.Get(17, i) != right.Get(1, i) || left.Get(15, i) != right.Get(-1, i) {
+			t.Errorf("The chunks to the left and right differ at row %d", i)
+		}
+		if left.Get(i, 16) != below.Get(i, 0) {
+			t.Errorf("The chunks above and below differ at column %d", i)
+		}
+	}
+
+	single := cb.Build(0, 0)
+	for i := range single.samples.Values {
+		if single.samples.Values[i] != right.samples.Values[i] {
+			t.Fatalf("Build and BuildChunks differ at %d", i)
+		}
+	}
+
+	interior := right.Interior()
+	if interior.Width != 17 || interior.Values[0] != right.Get(0, 0) || interior.Bounds.MinX != 0.0 || interior.Bounds.MaxX != float64(17)*cb.Step {
+		t.Errorf("Interior returned the wrong samples: %v", interior.Bounds)
+	}
+
+	// the vertices and normals along the seam of the meshes are identical
+	lm, rm := left.Mesh(1.0, 10.0, 0.0), right.Mesh(1.0, 10.0, 0.0)
+	if len(lm.Vertices) != 17*17 {
+		t.Fatalf("Chunk.Mesh built %d vertices", len(lm.Vertices))
+	}
+	seam := make(map[Vec3f]Vec3f)
+	for i, v := range lm.Vertices {
+		if v.X == 0.0 {
+			seam[v] = lm.Normals[i]
+		}
+	}
+	matched := 0
+	for i, v := range rm.Vertices {
+		if n, ok := seam[v]; ok {
+			if n != rm.Normals[i] {
+				t.Errorf("The normals at %v differ: %v and %v", v, n, rm.Normals[i])
+			}
+			matched++
+		}
+	}
+	if matched != 17 {
+		t.Errorf("Only %d of the 17 seam vertices matched", matched)
+	}
+}
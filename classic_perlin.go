@@ -0,0 +1,199 @@
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+/*
+
+This is an implementation of Ken Perlin's improved noise from 2002, which uses
+a quintic fade curve and a fixed set of twelve gradients picked by hashing the
+lattice coordinates. Unlike PerlinGenerator it matches the output of the
+reference implementation (and most shader implementations) exactly when it is
+built with the reference permutation table.
+
+Reference material:
+* Improved noise reference implementation: http://mrl.nyu.edu/~perlin/noise/
+* Improving Noise paper: http://mrl.nyu.edu/~perlin/paper445.pdf
+
+*/
+
+import (
+	"math"
+)
+
+var (
+	// PerlinReferencePermutation is the permutation table used by Ken Perlin's
+	// reference implementation of improved noise.
+	PerlinReferencePermutation = []int{
+		151, 160, 137, 91, 90, 15, 131, 13, 201, 95, 96, 53, 194, 233, 7, 225,
+		140, 36, 103, 30, 69, 142, 8, 99, 37, 240, 21, 10, 23, 190, 6, 148,
+		247, 120, 234, 75, 0, 26, 197, 62, 94, 252, 219, 203, 117, 35, 11, 32,
+		57, 177, 33, 88, 237, 149, 56, 87, 174, 20, 125, 136, 171, 168, 68, 175,
+		74, 165, 71, 134, 139, 48, 27, 166, 77, 146, 158, 231, 83, 111, 229, 122,
+		60, 211, 133, 230, 220, 105, 92, 41, 55, 46, 245, 40, 244, 102, 143, 54,
+		65, 25, 63, 161, 1, 216, 80, 73, 209, 76, 132, 187, 208, 89, 18, 169,
+		200, 196, 135, 130, 116, 188, 159, 86, 164, 100, 109, 198, 173, 186, 3, 64,
+		52, 217, 226, 250, 124, 123, 5, 202, 38, 147, 118, 126, 255, 82, 85, 212,
+		207, 206, 59, 227, 47, 16, 58, 17, 182, 189, 28, 42, 223, 183, 170, 213,
+		119, 248, 152, 2, 44, 154, 163, 70, 221, 153, 101, 155, 167, 43, 172, 9,
+		129, 22, 39, 253, 19, 98, 108, 110, 79, 113, 224, 232, 178, 185, 112, 104,
+		218, 246, 97, 228, 251, 34, 242, 193, 238, 210, 144, 12, 191, 179, 162, 241,
+		81, 51, 145, 235, 249, 14, 239, 107, 49, 192, 214, 31, 181, 199, 106, 157,
+		184, 84, 204, 176, 115, 121, 50, 45, 127, 4, 150, 254, 138, 236, 205, 93,
+		222, 114, 67, 29, 24, 72, 243, 141, 128, 195, 78, 66, 215, 61, 156, 180,
+	}
+)
+
+// ClassicPerlinGenerator stores the state information for generating
+// Ken Perlin's improved noise.
+type ClassicPerlinGenerator struct {
+	Rng          RandomSource // random number generator interface; nil unless built with NewClassicPerlinGenerator()
+	Permutations []int        // the random permutation table
+}
+
+// NewClassicPerlinGenerator creates a new state object for the improved perlin
+// noise generator using a permutation table built with the random number generator.
+func NewClassicPerlinGenerator(rng RandomSource) (cpg ClassicPerlinGenerator) {
+	cpg.Rng = rng
+	cpg.Permutations = rng.Perm(tableSize)
+	return
+}
+
+// NewClassicPerlinGeneratorSeed creates a new state object for the improved perlin
+// noise generator whose permutation table is built from seed. This produces the
+// same generator as passing NewPCG32(seed) to NewClassicPerlinGenerator() without
+// keeping the RNG around.
+func NewClassicPerlinGeneratorSeed(seed int64) (cpg ClassicPerlinGenerator) {
+	cpg.Reseed(seed)
+	return
+}
+
+// NewClassicPerlinGeneratorFromPermutation creates a new state object for the
+// improved perlin noise generator using a copy of perm, which must be a permutation
+// of 0..255, as the permutation table.
+func NewClassicPerlinGeneratorFromPermutation(perm []int) (cpg ClassicPerlinGenerator, err error) {
+	err = checkPermutation(perm, tableSize)
+	if err != nil {
+		return
+	}
+	cpg.Permutations = make([]int, tableSize)
+	copy(cpg.Permutations, perm)
+	return
+}
+
+// NewReferenceClassicPerlinGenerator creates a new state object for the improved
+// perlin noise generator that uses PerlinReferencePermutation so that the output
+// matches Ken Perlin's reference implementation.
+func NewReferenceClassicPerlinGenerator() (cpg ClassicPerlinGenerator) {
+	cpg, _ = NewClassicPerlinGeneratorFromPermutation(PerlinReferencePermutation)
+	return
+}
+
+// Reseed rebuilds the permutation table from seed, the same way
+// NewClassicPerlinGeneratorSeed() does, and drops the Rng.
+func (cpg *ClassicPerlinGenerator) Reseed(seed int64) {
+	cpg.Rng = nil
+	cpg.Permutations = NewPCG32(seed).Perm(tableSize)
+}
+
+// classicGradient returns the dot product of the fractional coordinates with
+// one of the twelve reference gradients selected by the low bits of the hash.
+func classicGradient(hash int, x, y, z float64) float64 {
+	h := hash & 15
+	var u, v float64
+	if h < 8 {
+		u = x
+	} else {
+		u = y
+	}
+	if h < 4 {
+		v = y
+	} else if h == 12 || h == 14 {
+		v = x
+	} else {
+		v = z
+	}
+	if h&1 != 0 {
+		u = -u
+	}
+	if h&2 != 0 {
+		v = -v
+	}
+	return u + v
+}
+
+// Get3D calculates the improved perlin noise at a given 3D coordinate
+func (cpg *ClassicPerlinGenerator) Get3D(x, y, z float64) float64 {
+	p := cpg.Permutations
+
+	// find the unit cube that contains the point
+	floored := Vec3f{math.Floor(x), math.Floor(y), math.Floor(z)}
+	xi := int(floored.X) & 0xFF
+	yi := int(floored.Y) & 0xFF
+	zi := int(floored.Z) & 0xFF
+
+	// find the relative position of the point in the cube
+	x -= floored.X
+	y -= floored.Y
+	z -= floored.Z
+
+	// compute the fade curves for each of x, y, z
+	u := calcQuinticSCurve(x)
+	v := calcQuinticSCurve(y)
+	w := calcQuinticSCurve(z)
+
+	// hash the coordinates of the 8 cube corners; the reference implementation
+	// doubles the table to avoid the masking done here
+	a := p[xi] + yi
+	aa := p[a&0xFF] + zi
+	ab := p[(a+1)&0xFF] + zi
+	b := p[(xi+1)&0xFF] + yi
+	ba := p[b&0xFF] + zi
+	bb := p[(b+1)&0xFF] + zi
+
+	// add the blended results from the 8 corners of the cube
+	return lerp(
+		lerp(
+			lerp(classicGradient(p[aa&0xFF], x, y, z), classicGradient(p[ba&0xFF], x-1, y, z), u),
+			lerp(classicGradient(p[ab&0xFF], x, y-1, z), classicGradient(p[bb&0xFF], x-1, y-1, z), u),
+			v),
+		lerp(
+			lerp(classicGradient(p[(aa+1)&0xFF], x, y, z-1), classicGradient(p[(ba+1)&0xFF], x-1, y, z-1), u),
+			lerp(classicGradient(p[(ab+1)&0xFF], x, y-1, z-1), classicGradient(p[(bb+1)&0xFF], x-1, y-1, z-1), u),
+			v),
+		w)
+}
+
+// Get2D calculates the improved perlin noise at a given 2D coordinate. This
+// is the z=0 slice of Get3D, matching what the reference implementation
+// returns for noise(x, y, 0), but only evaluates the 4 corners that contribute.
+func (cpg *ClassicPerlinGenerator) Get2D(x, y float64) float64 {
+	p := cpg.Permutations
+
+	// find the unit square that contains the point
+	floored := Vec2f{math.Floor(x), math.Floor(y)}
+	xi := int(floored.X) & 0xFF
+	yi := int(floored.Y) & 0xFF
+
+	// find the relative position of the point in the square
+	x -= floored.X
+	y -= floored.Y
+
+	// compute the fade curves for each of x, y
+	u := calcQuinticSCurve(x)
+	v := calcQuinticSCurve(y)
+
+	// hash the coordinates of the 4 square corners
+	a := p[xi] + yi
+	aa := p[a&0xFF]
+	ab := p[(a+1)&0xFF]
+	b := p[(xi+1)&0xFF] + yi
+	ba := p[b&0xFF]
+	bb := p[(b+1)&0xFF]
+
+	// add the blended results from the 4 corners of the square
+	return lerp(
+		lerp(classicGradient(p[aa], x, y, 0), classicGradient(p[ba], x-1, y, 0), u),
+		lerp(classicGradient(p[ab], x, y-1, 0), classicGradient(p[bb], x-1, y-1, 0), u),
+		v)
+}
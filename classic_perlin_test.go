@@ -0,0 +1,54 @@
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+import (
+	"math"
+	"testing"
+)
+
+// values computed with Ken Perlin's reference implementation of improved noise
+var classicPerlinReferenceValues = []Vec4f{
+	{3.14, 42.0, 7.0, 0.13691995878400012},
+	{0.5, 0.5, 0.5, -0.25},
+	{-1.25, 2.75, -3.5, 0.40050268173217773},
+	{10.1, -20.2, 30.3, 0.3410360150525964},
+	{123.456, 78.9, 0.0, -0.2130169530107069},
+	{255.5, 255.5, 255.5, -0.875},
+	{-0.3, 0.7, 0.0, -0.42026105183999996},
+}
+
+func TestClassicPerlinReference(t *testing.T) {
+	const epsilon = 1e-12
+	cpg := NewReferenceClassicPerlinGenerator()
+
+	for _, ref := range classicPerlinReferenceValues {
+		v := cpg.Get3D(ref.X, ref.Y, ref.Z)
+		if math.Abs(v-ref.W) > epsilon {
+			t.Errorf("Get3D(%v, %v, %v) = %v; reference is %v", ref.X, ref.Y, ref.Z, v, ref.W)
+		}
+
+		// the 2D noise is the z=0 slice of the 3D noise
+		if ref.Z == 0.0 {
+			v = cpg.Get2D(ref.X, ref.Y)
+			if math.Abs(v-ref.W) > epsilon {
+				t.Errorf("Get2D(%v, %v) = %v; reference is %v", ref.X, ref.Y, v, ref.W)
+			}
+		}
+	}
+}
+
+func TestClassicPerlin2DMatches3DSlice(t *testing.T) {
+	cpg := NewReferenceClassicPerlinGenerator()
+	for y := -50; y < 50; y++ {
+		for x := -50; x < 50; x++ {
+			fx, fy := float64(x)*0.37, float64(y)*0.41
+			v2 := cpg.Get2D(fx, fy)
+			v3 := cpg.Get3D(fx, fy, 0.0)
+			if v2 != v3 {
+				t.Fatalf("Get2D(%v, %v) = %v but Get3D(%v, %v, 0) = %v", fx, fy, v2, fx, fy, v3)
+			}
+		}
+	}
+}
@@ -0,0 +1,442 @@
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+   See the LICENSE file for more details. */
+
+package main
+
+/*
+
+noisey is a command line tool that renders a generator from a NoiseJSON
+configuration file without needing OpenGL, so it runs fine on a headless box.
+
+Basic usage is:
+
+	go get github.com/tbogdala/noisey/cmd/noisey
+	noisey -config noise.json -gen basic -size 512x512 -o basic.png
+
+The output format is picked with -format:
+
+	png   - an 8 bit image; -color selects "gray" or "terrain" coloring
+	raw   - the values as little-endian float32s, row by row
+	ascii - a text image using the characters " .:-=+*#%@"
+	obj   - a Wavefront OBJ terrain mesh with normals and UVs
+	stl   - a binary STL terrain mesh
+
+Meshes put -spacing between neighbouring values and multiply the values by
+-height; -decimate merges flat regions whose heights differ by at most that.
+
+If -o isn't given, png and raw output go to <gen>.png or <gen>.raw and ascii
+output goes to stdout; "-o -" always writes to stdout.
+
+To review the structure of a configuration, -dot writes the graph of its
+sources and generators for Graphviz instead of rendering anything:
+
+	noisey -config noise.json -dot - | dot -Tpng -o noise_graph.png
+
+When the output looks wrong, -sheet renders a thumbnail of every source and
+generator over -bounds, -thumb pixels square, into one labeled PNG:
+
+	noisey -config noise.json -sheet stages.png -color terrain
+
+The seeds in the configuration can be overridden with -seed, either all at
+once (-seed 42) or by name (-seed Default=42,Other=7).
+
+The term subcommand previews a generator right in the terminal with 24-bit
+colors, fitting the image to the width of the terminal:
+
+	noisey term -config noise.json -gen basic -color terrain
+
+It takes the same -config, -gen, -bounds, -seed and -workers flags; -columns
+and -rows override the size of the terminal, and -rows 0 (the default) lets
+the image be as tall as it needs to be for the width.
+
+*/
+
+import (
+	"bufio"
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"image/png"
+	"io"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/tbogdala/noisey"
+)
+
+// options holds the command line flags.
+type options struct {
+	config  string
+	gen     string
+	width   int
+	height  int
+	bounds  noisey.Builder2DBounds
+	seeds   string
+	workers int
+	format  string
+	color   string
+	output  string
+	dot     string
+	sheet   string
+	thumb   int
+
+	// for mesh output
+	spacing  float64
+	vertical float64
+	decimate float64
+
+	// for the term subcommand
+	columns int
+	rows    int
+}
+
+func main() {
+	var opts *options
+	var err error
+	term := len(os.Args) > 1 && os.Args[1] == "term"
+	if term {
+		opts, err = parseTermFlags(os.Args[2:])
+	} else {
+		opts, err = parseFlags(os.Args[1:])
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(2)
+	}
+
+	if term {
+		err = runTerm(opts)
+	} else {
+		err = run(opts)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "noisey: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// parseFlags reads the command line flags into an options structure.
+func parseFlags(args []string) (*options, error) {
+	opts := new(options)
+	var size, bounds string
+
+	flags := flag.NewFlagSet("noisey", flag.ContinueOnError)
+	addCommonFlags(flags, opts, &bounds)
+	flags.StringVar(&size, "size", "512x512", "the size of the output as WIDTHxHEIGHT")
+	flags.StringVar(&opts.format, "format", "png", "the output format: png, raw, ascii, obj or stl")
+	flags.Float64Var(&opts.spacing, "spacing", 1.0, "the distance between neighbouring values in obj and stl meshes")
+	flags.Float64Var(&opts.vertical, "height", 32.0, "what the values are multiplied by for the height of obj and stl meshes")
+	flags.Float64Var(&opts.decimate, "decimate", 0.0, "merges flat regions of obj and stl meshes whose heights differ by at most this much")
+	flags.StringVar(&opts.color, "color", "gray", "the coloring of png output: gray or terrain")
+	flags.StringVar(&opts.output, "o", "", "the output file; - writes to stdout")
+	flags.StringVar(&opts.dot, "dot", "", "writes the graph of the configuration in Graphviz DOT format to this file (- for stdout) instead of rendering")
+	flags.StringVar(&opts.sheet, "sheet", "", "writes a PNG contact sheet of every source and generator to this file (- for stdout) instead of rendering")
+	flags.IntVar(&opts.thumb, "thumb", 128, "the size of the thumbnails on the contact sheet")
+	err := parseCommonFlags(flags, args, opts, bounds)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = fmt.Sscanf(size, "%dx%d", &opts.width, &opts.height)
+	if err != nil || opts.width <= 0 || opts.height <= 0 {
+		return nil, fmt.Errorf("Invalid size (%s); expected WIDTHxHEIGHT.", size)
+	}
+
+	switch opts.format {
+	case "png", "raw", "ascii", "obj", "stl":
+	default:
+		return nil, fmt.Errorf("Undefined output format (%s).", opts.format)
+	}
+
+	if opts.thumb < 1 {
+		return nil, fmt.Errorf("Invalid thumbnail size (%d).", opts.thumb)
+	}
+
+	if opts.output == "" {
+		if opts.format == "ascii" {
+			opts.output = "-"
+		} else {
+			opts.output = opts.gen + "." + opts.format
+		}
+	}
+
+	return opts, nil
+}
+
+// parseTermFlags reads the command line flags of the term subcommand.
+func parseTermFlags(args []string) (*options, error) {
+	opts := new(options)
+	var bounds string
+
+	flags := flag.NewFlagSet("noisey term", flag.ContinueOnError)
+	addCommonFlags(flags, opts, &bounds)
+	flags.StringVar(&opts.color, "color", "terrain", "the coloring: gray or terrain")
+	flags.IntVar(&opts.columns, "columns", 0, "the width of the terminal; 0 asks the terminal")
+	flags.IntVar(&opts.rows, "rows", 0, "the maximum number of rows of text to use; 0 for no limit")
+	err := parseCommonFlags(flags, args, opts, bounds)
+	if err != nil {
+		return nil, err
+	}
+	if opts.columns < 0 || opts.rows < 0 {
+		return nil, fmt.Errorf("The columns and rows can't be negative.")
+	}
+	return opts, nil
+}
+
+// addCommonFlags adds the flags shared by all of the commands to flags.
+func addCommonFlags(flags *flag.FlagSet, opts *options, bounds *string) {
+	flags.StringVar(&opts.config, "config", "noise.json", "the NoiseJSON configuration file to load")
+	flags.StringVar(&opts.gen, "gen", "basic", "the name of the generator to render")
+	flags.StringVar(bounds, "bounds", "0,0,5.12,5.12", "the area of noise to render as MINX,MINY,MAXX,MAXY")
+	flags.StringVar(&opts.seeds, "seed", "", "overrides the seeds: a single value for all of them or NAME=VALUE pairs separated by commas")
+	flags.IntVar(&opts.workers, "workers", runtime.NumCPU(), "the number of goroutines used to build the noise")
+}
+
+// parseCommonFlags parses args and checks the flags shared by all of the commands.
+func parseCommonFlags(flags *flag.FlagSet, args []string, opts *options, bounds string) error {
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return fmt.Errorf("Unexpected arguments: %s", strings.Join(flags.Args(), " "))
+	}
+
+	b := &opts.bounds
+	_, err = fmt.Sscanf(bounds, "%g,%g,%g,%g", &b.MinX, &b.MinY, &b.MaxX, &b.MaxY)
+	if err != nil {
+		return fmt.Errorf("Invalid bounds (%s); expected MINX,MINY,MAXX,MAXY.", bounds)
+	}
+
+	switch opts.color {
+	case "gray", "terrain":
+	default:
+		return fmt.Errorf("Undefined coloring (%s).", opts.color)
+	}
+	return nil
+}
+
+// loadConfig loads the configuration and applies the seed overrides.
+func loadConfig(opts *options) (*noisey.NoiseJSON, error) {
+	bank, err := noisey.LoadNoiseJSONFile(opts.config)
+	if err != nil {
+		return nil, err
+	}
+
+	err = overrideSeeds(bank, opts.seeds)
+	if err != nil {
+		return nil, err
+	}
+	return bank, nil
+}
+
+// buildConfig loads the configuration and builds all of its sources and generators.
+func buildConfig(opts *options) (*noisey.NoiseJSON, error) {
+	bank, err := loadConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// the last sample caches don't work across goroutines
+	if opts.workers > 1 {
+		bank.AutoCache = false
+	}
+
+	err = bank.BuildSources(nil)
+	if err != nil {
+		return nil, err
+	}
+	err = bank.BuildGenerators()
+	if err != nil {
+		return nil, err
+	}
+	return bank, nil
+}
+
+// loadGenerator loads the configuration and builds the generator to render.
+func loadGenerator(opts *options) (noisey.NoiseyGet2D, error) {
+	bank, err := buildConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	gen := bank.GetGenerator(opts.gen)
+	if gen == nil {
+		return nil, fmt.Errorf("Generator \"%s\" wasn't found in %s.", opts.gen, opts.config)
+	}
+	return gen, nil
+}
+
+// runTerm builds the generator at the size of the terminal and draws it there.
+func runTerm(opts *options) error {
+	gen, err := loadGenerator(opts)
+	if err != nil {
+		return err
+	}
+
+	columns, _ := noisey.TerminalSize(os.Stdout)
+	if opts.columns > 0 {
+		columns = opts.columns
+	}
+	width, height := noisey.TerminalFit(opts.bounds, columns, opts.rows)
+
+	builder := noisey.NewBuilder2D(gen, width, height)
+	builder.Bounds = opts.bounds
+	builder.BuildParallel(opts.workers)
+
+	gradient := noisey.GrayscaleGradient
+	if opts.color == "terrain" {
+		gradient = noisey.TerrainGradient
+	}
+	return builder.WriteANSI(os.Stdout, gradient)
+}
+
+// run loads the configuration, builds the generator and writes the output.
+func run(opts *options) error {
+	if opts.dot != "" {
+		bank, err := loadConfig(opts)
+		if err != nil {
+			return err
+		}
+		return writeOutput(opts.dot, bank.WriteDOT)
+	}
+
+	if opts.sheet != "" {
+		bank, err := buildConfig(opts)
+		if err != nil {
+			return err
+		}
+		gradient := noisey.GrayscaleGradient
+		if opts.color == "terrain" {
+			gradient = noisey.TerrainGradient
+		}
+		return writeOutput(opts.sheet, func(w io.Writer) error {
+			return bank.WriteContactSheet(w, opts.bounds, opts.thumb, gradient)
+		})
+	}
+
+	gen, err := loadGenerator(opts)
+	if err != nil {
+		return err
+	}
+
+	builder := noisey.NewBuilder2D(gen, opts.width, opts.height)
+	builder.Bounds = opts.bounds
+	builder.BuildParallel(opts.workers)
+
+	return writeOutput(opts.output, func(w io.Writer) error {
+		switch opts.format {
+		case "raw":
+			return writeRaw(w, &builder)
+		case "ascii":
+			return writeASCII(w, &builder)
+		case "obj":
+			return builder.Mesh(opts.spacing, opts.vertical, opts.decimate).WriteOBJ(w)
+		case "stl":
+			return builder.Mesh(opts.spacing, opts.vertical, opts.decimate).WriteSTL(w)
+		}
+		return writePNG(w, &builder, opts.color)
+	})
+}
+
+// writeOutput calls write with a buffered writer for the file at path, or
+// for stdout if path is "-".
+func writeOutput(path string, write func(io.Writer) error) error {
+	if path != "-" {
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		err = writeBuffered(f, write)
+		// a failed close can lose the end of the file, so report it too
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		return err
+	}
+	return writeBuffered(os.Stdout, write)
+}
+
+// writeBuffered calls write with a buffered writer on out and flushes it.
+func writeBuffered(out io.Writer, write func(io.Writer) error) error {
+	w := bufio.NewWriter(out)
+	err := write(w)
+	if err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// overrideSeeds applies the -seed flag to the seeds of the configuration.
+func overrideSeeds(bank *noisey.NoiseJSON, seeds string) error {
+	if seeds == "" {
+		return nil
+	}
+
+	// a single value overrides all of the seeds
+	if all, err := strconv.ParseInt(seeds, 10, 64); err == nil {
+		for name := range bank.Seeds {
+			bank.Seeds[name] = all
+		}
+		return nil
+	}
+
+	for _, pair := range strings.Split(seeds, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("Invalid seed override (%s); expected NAME=VALUE.", pair)
+		}
+		value, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("Invalid seed value (%s) for seed %s.", parts[1], parts[0])
+		}
+		if _, ok := bank.Seeds[parts[0]]; !ok {
+			return fmt.Errorf("Seed \"%s\" isn't defined in the configuration.", parts[0])
+		}
+		bank.Seeds[parts[0]] = value
+	}
+	return nil
+}
+
+// writePNG encodes the values as a grayscale or colorized PNG image.
+func writePNG(w io.Writer, builder *noisey.Builder2D, coloring string) error {
+	if coloring == "terrain" {
+		return png.Encode(w, builder.Image(noisey.TerrainGradient))
+	}
+	return png.Encode(w, builder.GrayImage())
+}
+
+// writeRaw writes the values as little-endian float32s.
+func writeRaw(w io.Writer, builder *noisey.Builder2D) error {
+	values := make([]float32, len(builder.Values))
+	for i, v := range builder.Values {
+		values[i] = float32(v)
+	}
+	return binary.Write(w, binary.LittleEndian, values)
+}
+
+// writeASCII writes the values as a text image.
+func writeASCII(w io.Writer, builder *noisey.Builder2D) error {
+	const ramp = " .:-=+*#%@"
+	line := make([]byte, builder.Width+1)
+	line[builder.Width] = '\n'
+	for y := 0; y < builder.Height; y++ {
+		for x := 0; x < builder.Width; x++ {
+			v := builder.Values[y*builder.Width+x]*0.5 + 0.5
+			i := int(v * float64(len(ramp)))
+			if i < 0 {
+				i = 0
+			} else if i >= len(ramp) {
+				i = len(ramp) - 1
+			}
+			line[x] = ramp[i]
+		}
+		_, err := w.Write(line)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
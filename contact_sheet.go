@@ -0,0 +1,167 @@
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+/*
+
+This module renders a contact sheet of a built NoiseJSON: a thumbnail of
+every source and generator over the same bounds, labeled with its name, type
+and the range of its values. When the final generator looks wrong, the sheet
+shows which stage of the graph it goes wrong in:
+
+  err = noiseBank.WriteContactSheet(file, noisey.Builder2DBounds{0.0, 0.0, 4.0, 4.0}, 128, noisey.GrayscaleGradient)
+
+The sources come first, sorted by name, followed by the generators in the
+order they're configured. Generators of the "3d" types are drawn as the slice
+at z = 0.
+
+*/
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"sort"
+)
+
+const (
+	// the space around each thumbnail on a contact sheet
+	sheetPadding = 6
+
+	// the number of text lines under each thumbnail
+	sheetLabelLines = 3
+)
+
+var (
+	sheetBackground = color.RGBA{32, 32, 32, 255}
+	sheetText       = color.RGBA{230, 230, 230, 255}
+	sheetWarning    = color.RGBA{255, 96, 96, 255}
+)
+
+// sheetNode is one of the thumbnails on a contact sheet.
+type sheetNode struct {
+	name, kind string
+	source     NoiseyGet2D
+}
+
+// sliceZ samples a 3D module on the plane z = Z.
+type sliceZ struct {
+	Source NoiseyGet3D
+	Z      float64
+}
+
+// Get2D returns the value of Source at (x, y, Z).
+func (s *sliceZ) Get2D(x float64, y float64) float64 {
+	return s.Source.Get3D(x, y, s.Z)
+}
+
+// ContactSheet renders a thumbnail size x size pixels big of every built source
+// and generator over bounds, colored with gradient, and arranges them in a
+// labeled grid that's roughly square. Values outside of -1..1, or that aren't
+// numbers, get their range printed in red. This function Must be called after
+// both BuildSources() and BuildGenerators().
+func (cfg *NoiseJSON) ContactSheet(bounds Builder2DBounds, size int, gradient ColorGradient) *image.RGBA {
+	nodes := cfg.sheetNodes()
+	if size < 1 {
+		size = 1
+	}
+
+	columns := int(math.Ceil(math.Sqrt(float64(len(nodes)))))
+	if columns < 1 {
+		columns = 1
+	}
+	rows := (len(nodes) + columns - 1) / columns
+	cellW := size + sheetPadding
+	cellH := size + sheetPadding + sheetLabelLines*fontHeight
+
+	img := image.NewRGBA(image.Rect(0, 0, columns*cellW+sheetPadding, rows*cellH+sheetPadding))
+	draw.Draw(img, img.Bounds(), &image.Uniform{sheetBackground}, image.ZP, draw.Src)
+
+	maxChars := size / fontAdvance
+	builder := NewBuilder2D(nil, size, size)
+	builder.Bounds = bounds
+	for i, node := range nodes {
+		x0 := sheetPadding + (i%columns)*cellW
+		y0 := sheetPadding + (i/columns)*cellH
+
+		builder.Source = node.source
+		builder.Build()
+		for y := 0; y < size; y++ {
+			for x := 0; x < size; x++ {
+				img.SetRGBA(x0+x, y0+y, gradient.Color(builder.Values[y*size+x]))
+			}
+		}
+
+		low, high := math.Inf(1), math.Inf(-1)
+		valid := true
+		for _, v := range builder.Values {
+			if math.IsNaN(v) {
+				valid = false
+				continue
+			}
+			low = math.Min(low, v)
+			high = math.Max(high, v)
+		}
+		rangeColor := sheetText
+		if !valid || low < -1.0 || high > 1.0 {
+			rangeColor = sheetWarning
+		}
+		rangeText := fmt.Sprintf("%.2f..%.2f", low, high)
+		if !valid {
+			rangeText += " NaN"
+		}
+
+		y := y0 + size + 2
+		drawText(img, x0, y, truncateLabel(node.name, maxChars), sheetText)
+		drawText(img, x0, y+fontHeight, truncateLabel(node.kind, maxChars), sheetText)
+		drawText(img, x0, y+2*fontHeight, truncateLabel(rangeText, maxChars), rangeColor)
+	}
+
+	return img
+}
+
+// WriteContactSheet writes the image made by ContactSheet() to w as a PNG.
+func (cfg *NoiseJSON) WriteContactSheet(w io.Writer, bounds Builder2DBounds, size int, gradient ColorGradient) error {
+	return png.Encode(w, cfg.ContactSheet(bounds, size, gradient))
+}
+
+// sheetNodes returns the built sources and generators in the order they go
+// on a contact sheet.
+func (cfg *NoiseJSON) sheetNodes() []sheetNode {
+	var nodes []sheetNode
+
+	names := make([]string, 0, len(cfg.builtSources))
+	for name := range cfg.builtSources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		nodes = append(nodes, sheetNode{name, cfg.Sources[name].SourceType, cfg.builtSources[name]})
+	}
+
+	for _, gen := range cfg.Generators {
+		if g, ok := cfg.builtGenerators[gen.Name]; ok {
+			nodes = append(nodes, sheetNode{gen.Name, gen.GeneratorType, g})
+		} else if g3, ok := cfg.builtGenerators3D[gen.Name]; ok {
+			nodes = append(nodes, sheetNode{gen.Name, gen.GeneratorType, &sliceZ{g3, 0.0}})
+		}
+	}
+	return nodes
+}
+
+// truncateLabel shortens s to at most max characters, marking it with ".."
+// if it had to be cut.
+func truncateLabel(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	if max <= 2 {
+		return s[:max]
+	}
+	return s[:max-2] + ".."
+}
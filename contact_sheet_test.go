@@ -0,0 +1,63 @@
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+import (
+	"testing"
+)
+
+func TestContactSheet(t *testing.T) {
+	json := []byte(`{
+		"Seeds": { "Default": 1 },
+		"Sources": {
+			"perlin": { "SourceType": "perlin", "Seed": "Default" },
+			"half": { "SourceType": "const", "Value": 0.5 }
+		},
+		"Generators": [
+			{ "Name": "fbm", "GeneratorType": "fBm2d", "Sources": ["perlin"], "Octaves": 2, "Persistence": 0.5, "Lacunarity": 2.0, "Frequency": 1.0 },
+			{ "Name": "fbm3", "GeneratorType": "fBm3d", "Sources": ["half"], "Octaves": 1, "Persistence": 0.5, "Lacunarity": 2.0, "Frequency": 1.0 }
+		]
+	}`)
+	cfg, err := LoadNoiseJSON(json)
+	if err != nil {
+		t.Fatalf("Failed to load the configuration: %v", err)
+	}
+	if err = cfg.BuildSources(nil); err != nil {
+		t.Fatalf("Failed to build the sources: %v", err)
+	}
+	if err = cfg.BuildGenerators(); err != nil {
+		t.Fatalf("Failed to build the generators: %v", err)
+	}
+
+	// four thumbnails go on a 2x2 grid: half, perlin, fbm, fbm3
+	const size = 32
+	img := cfg.ContactSheet(Builder2DBounds{0.0, 0.0, 2.0, 2.0}, size, GrayscaleGradient)
+	cellW := size + sheetPadding
+	cellH := size + sheetPadding + sheetLabelLines*fontHeight
+	if img.Bounds().Dx() != 2*cellW+sheetPadding || img.Bounds().Dy() != 2*cellH+sheetPadding {
+		t.Fatalf("ContactSheet has an unexpected size: %v", img.Bounds())
+	}
+
+	// the constant source and the 3d slice of it are flat 0.5 gray
+	gray := GrayscaleGradient.Color(0.5)
+	if img.RGBAAt(sheetPadding+size/2, sheetPadding+size/2) != gray {
+		t.Errorf("The first thumbnail isn't the constant source")
+	}
+	if img.RGBAAt(sheetPadding+cellW+size/2, sheetPadding+cellH+size/2) != gray {
+		t.Errorf("The last thumbnail isn't the 3d slice of the constant source")
+	}
+
+	// the labels get drawn under the thumbnails
+	labelled := false
+	for x := sheetPadding; x < sheetPadding+size; x++ {
+		for y := sheetPadding + size; y < cellH; y++ {
+			if img.RGBAAt(x, y) == sheetText {
+				labelled = true
+			}
+		}
+	}
+	if !labelled {
+		t.Errorf("ContactSheet didn't draw the labels")
+	}
+}
@@ -0,0 +1,383 @@
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+/*
+
+This module writes noise graphs in the DOT language of Graphviz
+(http://www.graphviz.org/) so that they can be looked at as a picture:
+
+  f, _ := os.Create("noise.dot")
+  noiseBank.WriteDOT(f)
+  f.Close()
+
+and then run something like:
+
+  dot -Tpng noise.dot -o noise.png
+
+WriteDOT draws the configuration of a NoiseJSON: sources are ellipses,
+generators are boxes and the arrows point from the inputs to the generators
+using them. Each node lists its name, type and key parameters; parameters
+given as expressions show the expression. References to sources or
+generators that aren't defined are drawn as dashed red nodes so that broken
+configurations can be inspected too.
+
+WriteModuleDOT draws a graph of built modules instead, starting from one
+module and following its inputs. That shows what is actually being sampled,
+including the caches added by NoiseJSON.AutoCache.
+
+*/
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// dotWriter writes the nodes and edges of a DOT graph.
+type dotWriter struct {
+	out *bufio.Writer
+}
+
+// newDotWriter starts a new directed graph on w.
+func newDotWriter(w io.Writer) *dotWriter {
+	dw := &dotWriter{out: bufio.NewWriter(w)}
+	dw.out.WriteString("digraph noise {\n")
+	dw.out.WriteString("\trankdir=LR;\n")
+	dw.out.WriteString("\tnode [fontname=\"Helvetica\", fontsize=10];\n")
+	dw.out.WriteString("\tedge [fontname=\"Helvetica\", fontsize=9];\n")
+	return dw
+}
+
+// node writes a node whose label is made of the lines given.
+func (dw *dotWriter) node(id string, attrs string, lines []string) {
+	quoted := make([]string, len(lines))
+	for i, l := range lines {
+		quoted[i] = dotEscape(l)
+	}
+	fmt.Fprintf(dw.out, "\t\"%s\" [%s, label=\"%s\"];\n", dotEscape(id), attrs, strings.Join(quoted, "\\n"))
+}
+
+// edge writes an edge, labeling it if label isn't empty.
+func (dw *dotWriter) edge(from, to, label string) {
+	if label == "" {
+		fmt.Fprintf(dw.out, "\t\"%s\" -> \"%s\";\n", dotEscape(from), dotEscape(to))
+	} else {
+		fmt.Fprintf(dw.out, "\t\"%s\" -> \"%s\" [label=\"%s\"];\n", dotEscape(from), dotEscape(to), dotEscape(label))
+	}
+}
+
+// close ends the graph and flushes the output.
+func (dw *dotWriter) close() error {
+	dw.out.WriteString("}\n")
+	return dw.out.Flush()
+}
+
+// dotEscape escapes the characters that can't appear in a quoted DOT string.
+func dotEscape(s string) string {
+	s = strings.Replace(s, "\\", "\\\\", -1)
+	s = strings.Replace(s, "\"", "\\\"", -1)
+	return strings.Replace(s, "\n", "\\n", -1)
+}
+
+// dotParam formats a parameter for a node label.
+func dotParam(name string, value interface{}) string {
+	return fmt.Sprintf("%s: %v", name, value)
+}
+
+// selectInputLabels are the edge labels for the inputs of select modules.
+var selectInputLabels = []string{"a", "b", "control"}
+
+/* ------------------------------------------------------------------------- */
+
+// WriteDOT writes the graph of sources and generators in the configuration
+// to w in the DOT language. It doesn't need BuildSources() or BuildGenerators()
+// to have been called.
+func (cfg *NoiseJSON) WriteDOT(w io.Writer) error {
+	dw := newDotWriter(w)
+
+	sourceNames := make([]string, 0, len(cfg.Sources))
+	for name := range cfg.Sources {
+		sourceNames = append(sourceNames, name)
+	}
+	sort.Strings(sourceNames)
+	for _, name := range sourceNames {
+		dw.node("Source/"+name, "shape=ellipse", cfg.sourceLabel(name, cfg.Sources[name]))
+	}
+
+	defined := make(map[string]bool)
+	for _, gen := range cfg.Generators {
+		defined[gen.Name] = true
+		dw.node("Generator/"+gen.Name, "shape=box", generatorLabel(gen))
+	}
+
+	missing := make(map[string]bool)
+	for _, gen := range cfg.Generators {
+		to := "Generator/" + gen.Name
+		for _, name := range gen.Sources {
+			from := "Source/" + name
+			if _, ok := cfg.Sources[name]; !ok && !missing[from] {
+				missing[from] = true
+				dw.node(from, "shape=ellipse, style=dashed, color=red", []string{name, "(missing source)"})
+			}
+			dw.edge(from, to, "")
+		}
+		for i, name := range gen.Generators {
+			from := "Generator/" + name
+			if !defined[name] && !missing[from] {
+				missing[from] = true
+				dw.node(from, "shape=box, style=dashed, color=red", []string{name, "(missing generator)"})
+			}
+			label := ""
+			if strings.HasPrefix(gen.GeneratorType, "select") && i < len(selectInputLabels) {
+				label = selectInputLabels[i]
+			}
+			dw.edge(from, to, label)
+		}
+	}
+
+	return dw.close()
+}
+
+// sourceLabel returns the label lines for a source in the configuration.
+func (cfg *NoiseJSON) sourceLabel(name string, source SourceJSON) []string {
+	lines := []string{name, source.SourceType}
+	param := func(field string, value interface{}) {
+		if expr, ok := source.Expressions[field]; ok {
+			lines = append(lines, dotParam(field, expr))
+		} else {
+			lines = append(lines, dotParam(field, value))
+		}
+	}
+
+	switch source.SourceType {
+	case "const":
+		param("Value", source.Value)
+		return lines
+	case "checkerboard", "cylinders", "spheres":
+		param("Frequency", source.Frequency)
+		return lines
+	}
+
+	if seed, ok := cfg.Seeds[source.Seed]; ok {
+		lines = append(lines, fmt.Sprintf("Seed: %s (%d)", source.Seed, seed))
+	} else {
+		lines = append(lines, fmt.Sprintf("Seed: %s (missing)", source.Seed))
+	}
+	if source.Quality != nil {
+		param("Quality", *source.Quality)
+	} else if expr, ok := source.Expressions["Quality"]; ok {
+		param("Quality", expr)
+	}
+	if source.FullHash {
+		lines = append(lines, "FullHash")
+	}
+	if len(source.Period) > 0 {
+		lines = append(lines, dotParam("Period", source.Period))
+	}
+	return lines
+}
+
+// generatorLabel returns the label lines for a generator in the configuration.
+func generatorLabel(gen GeneratorJSON) []string {
+	lines := []string{gen.Name, gen.GeneratorType}
+	param := func(field string, value interface{}) {
+		if expr, ok := gen.Expressions[field]; ok {
+			lines = append(lines, dotParam(field, expr))
+		} else {
+			lines = append(lines, dotParam(field, value))
+		}
+	}
+
+	switch strings.TrimSuffix(strings.TrimSuffix(gen.GeneratorType, "2d"), "3d") {
+	case "fBm":
+		param("Octaves", gen.Octaves)
+		param("Persistence", gen.Persistence)
+		param("Lacunarity", gen.Lacunarity)
+		param("Frequency", gen.Frequency)
+	case "select":
+		param("LowerBound", gen.LowerBound)
+		param("UpperBound", gen.UpperBound)
+		param("EdgeFalloff", gen.EdgeFalloff)
+	case "scale":
+		param("Scale", gen.Scale)
+		param("Bias", gen.Bias)
+		param("Min", gen.Min)
+		param("Max", gen.Max)
+	case "clamp":
+		param("Min", gen.Min)
+		param("Max", gen.Max)
+	case "exponent":
+		param("Exponent", gen.Exponent)
+	case "normalize":
+		param("Min", gen.Min)
+		param("Max", gen.Max)
+		if gen.Samples > 0 || gen.Expressions["Samples"] != "" {
+			param("Samples", gen.Samples)
+		}
+	}
+	return lines
+}
+
+/* ------------------------------------------------------------------------- */
+
+// moduleInput is an input of a built module and the label of its edge.
+type moduleInput struct {
+	label  string
+	module interface{}
+}
+
+// WriteModuleDOT writes the graph of built modules reachable from module to w
+// in the DOT language. The node of module is labeled with name. Modules used
+// by several others only appear once; modules that noisey doesn't know about
+// are drawn with their type and without any inputs.
+func WriteModuleDOT(w io.Writer, name string, module interface{}) error {
+	dw := newDotWriter(w)
+	ids := make(map[uintptr]string)
+	count := 0
+
+	var visit func(m interface{}, title string) string
+	visit = func(m interface{}, title string) string {
+		v := reflect.ValueOf(m)
+		if v.Kind() == reflect.Ptr {
+			if id, ok := ids[v.Pointer()]; ok {
+				return id
+			}
+		}
+
+		id := fmt.Sprintf("m%d", count)
+		count++
+		if v.Kind() == reflect.Ptr {
+			ids[v.Pointer()] = id
+		}
+
+		typeName := reflect.Indirect(v).Type().Name()
+		params, inputs := describeModule(m)
+		lines := []string{typeName}
+		if title != "" {
+			lines = []string{title, typeName}
+		}
+		shape := "shape=box"
+		if len(inputs) == 0 {
+			shape = "shape=ellipse"
+		}
+		dw.node(id, shape, append(lines, params...))
+
+		for _, in := range inputs {
+			if isNilModule(in.module) {
+				continue
+			}
+			dw.edge(visit(in.module, ""), id, in.label)
+		}
+		return id
+	}
+
+	if !isNilModule(module) {
+		visit(module, name)
+	}
+	return dw.close()
+}
+
+// isNilModule returns true if m is nil or a nil pointer.
+func isNilModule(m interface{}) bool {
+	if m == nil {
+		return true
+	}
+	v := reflect.ValueOf(m)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
+
+// describeModule returns the key parameters and the inputs of a built module.
+func describeModule(m interface{}) (params []string, inputs []moduleInput) {
+	in := func(module interface{}) []moduleInput {
+		return []moduleInput{{"", module}}
+	}
+	sel := func(a, b, control interface{}) []moduleInput {
+		return []moduleInput{{selectInputLabels[0], a}, {selectInputLabels[1], b}, {selectInputLabels[2], control}}
+	}
+	fbm := func(octaves int, persistence, lacunarity, frequency float64) []string {
+		return []string{dotParam("Octaves", octaves), dotParam("Persistence", persistence),
+			dotParam("Lacunarity", lacunarity), dotParam("Frequency", frequency)}
+	}
+	hashing := func(fullHash bool, period Vec3i) (p []string) {
+		if fullHash {
+			p = append(p, "FullHash")
+		}
+		if period != (Vec3i{}) {
+			p = append(p, fmt.Sprintf("Period: %d %d %d", period.X, period.Y, period.Z))
+		}
+		return
+	}
+
+	switch t := m.(type) {
+	case *PerlinGenerator:
+		params = append([]string{dotParam("Quality", int(t.Quality))}, hashing(t.FullHash, t.Period)...)
+	case *OpenSimplexGenerator:
+		params = hashing(t.FullHash, Vec3i{})
+	case *ValueNoiseGenerator:
+		params = []string{dotParam("Quality", int(t.Quality))}
+	case *PerlinGenerator32:
+		params = []string{dotParam("Quality", int(t.Quality))}
+	case *ConstGenerator:
+		params = []string{dotParam("Value", t.Value)}
+	case *CheckerboardGenerator:
+		params = []string{dotParam("Frequency", t.Frequency)}
+	case *CylindersGenerator:
+		params = []string{dotParam("Frequency", t.Frequency)}
+	case *SpheresGenerator:
+		params = []string{dotParam("Frequency", t.Frequency)}
+	case *FBMGenerator2D:
+		params, inputs = fbm(t.Octaves, t.Persistence, t.Lacunarity, t.Frequency), in(t.NoiseMaker)
+	case *FBMGenerator3D:
+		params, inputs = fbm(t.Octaves, t.Persistence, t.Lacunarity, t.Frequency), in(t.NoiseMaker)
+	case *FBMGenerator2D32:
+		params, inputs = fbm(t.Octaves, float64(t.Persistence), float64(t.Lacunarity), float64(t.Frequency)), in(t.NoiseMaker)
+	case *FBMGenerator3D32:
+		params, inputs = fbm(t.Octaves, float64(t.Persistence), float64(t.Lacunarity), float64(t.Frequency)), in(t.NoiseMaker)
+	case *Select2D:
+		params = []string{dotParam("LowerBound", t.LowerBound), dotParam("UpperBound", t.UpperBound), dotParam("EdgeFalloff", t.EdgeFalloff)}
+		inputs = sel(t.SourceA, t.SourceB, t.Control)
+	case *Select3D:
+		params = []string{dotParam("LowerBound", t.LowerBound), dotParam("UpperBound", t.UpperBound), dotParam("EdgeFalloff", t.EdgeFalloff)}
+		inputs = sel(t.SourceA, t.SourceB, t.Control)
+	case *Scale2D:
+		params = []string{dotParam("Scale", t.Scale), dotParam("Bias", t.Bias), dotParam("Min", t.Min), dotParam("Max", t.Max)}
+		inputs = in(t.Source)
+	case *Scale3D:
+		params = []string{dotParam("Scale", t.Scale), dotParam("Bias", t.Bias), dotParam("Min", t.Min), dotParam("Max", t.Max)}
+		inputs = in(t.Source)
+	case *Abs2D:
+		inputs = in(t.Source)
+	case *Abs3D:
+		inputs = in(t.Source)
+	case *Invert2D:
+		inputs = in(t.Source)
+	case *Invert3D:
+		inputs = in(t.Source)
+	case *Clamp2D:
+		params, inputs = []string{dotParam("Min", t.Min), dotParam("Max", t.Max)}, in(t.Source)
+	case *Clamp3D:
+		params, inputs = []string{dotParam("Min", t.Min), dotParam("Max", t.Max)}, in(t.Source)
+	case *Exponent2D:
+		params, inputs = []string{dotParam("Exponent", t.Exponent)}, in(t.Source)
+	case *Exponent3D:
+		params, inputs = []string{dotParam("Exponent", t.Exponent)}, in(t.Source)
+	case *Normalize2D:
+		params = []string{fmt.Sprintf("In: %g..%g", t.InMin, t.InMax), fmt.Sprintf("Out: %g..%g", t.OutMin, t.OutMax)}
+		inputs = in(t.Source)
+	case *Normalize3D:
+		params = []string{fmt.Sprintf("In: %g..%g", t.InMin, t.InMax), fmt.Sprintf("Out: %g..%g", t.OutMin, t.OutMax)}
+		inputs = in(t.Source)
+	case *Cache2D:
+		inputs = in(t.Source)
+	case *Cache3D:
+		inputs = in(t.Source)
+	case *TileCache2D:
+		params, inputs = []string{dotParam("Step", t.Step), dotParam("TileSize", t.TileSize)}, in(t.Source)
+	}
+	return
+}
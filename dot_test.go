@@ -0,0 +1,72 @@
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestNoiseJSONWriteDOT(t *testing.T) {
+	json := []byte(`{
+		"Params": { "base": 1.5 },
+		"Seeds": { "Default": 7 },
+		"Sources": {
+			"perlin": { "SourceType": "perlin", "Seed": "Default", "Period": [8, 8] }
+		},
+		"Generators": [
+			{ "Name": "fbm", "GeneratorType": "fBm2d", "Sources": ["perlin"], "Octaves": 3, "Frequency": "$base * 2" },
+			{ "Name": "sel", "GeneratorType": "select2d", "Generators": ["fbm", "gone", "fbm"] }
+		]
+	}`)
+	cfg, err := LoadNoiseJSON(json)
+	if err != nil {
+		t.Fatalf("Failed to load the configuration: %v", err)
+	}
+
+	var out bytes.Buffer
+	err = cfg.WriteDOT(&out)
+	if err != nil {
+		t.Fatalf("WriteDOT failed: %v", err)
+	}
+
+	dot := out.String()
+	for _, expected := range []string{
+		`"Source/perlin" [shape=ellipse, label="perlin\nperlin\nSeed: Default (7)\nPeriod: [8 8]"];`,
+		`\nOctaves: 3\n`,
+		`\nFrequency: $base * 2"`,
+		`"Generator/gone" [shape=box, style=dashed, color=red`,
+		`"Source/perlin" -> "Generator/fbm";`,
+		`"Generator/fbm" -> "Generator/sel" [label="a"];`,
+		`"Generator/gone" -> "Generator/sel" [label="b"];`,
+		`"Generator/fbm" -> "Generator/sel" [label="control"];`,
+	} {
+		if !strings.Contains(dot, expected) {
+			t.Errorf("WriteDOT output is missing %s:\n%s", expected, dot)
+		}
+	}
+}
+
+func TestWriteModuleDOT(t *testing.T) {
+	perlin := NewPerlinGeneratorSeed(1)
+	fbm := NewFBMGenerator2D(&perlin, 2, 0.5, 2.0, 1.0)
+	cached := NewCache2D(&fbm)
+	scale := NewScale2D(&cached, 0.5, 0.0, -1.0, 1.0)
+	sel := NewSelect2D(&cached, &scale, &cached, 0.0, 1.0, 0.1)
+
+	var out bytes.Buffer
+	err := WriteModuleDOT(&out, "root", &sel)
+	if err != nil {
+		t.Fatalf("WriteModuleDOT failed: %v", err)
+	}
+
+	dot := out.String()
+	if strings.Count(dot, "Cache2D") != 1 || strings.Count(dot, "PerlinGenerator") != 1 {
+		t.Errorf("WriteModuleDOT didn't share the nodes of modules used twice:\n%s", dot)
+	}
+	if !strings.Contains(dot, `label="root\nSelect2D\nLowerBound: 0`) || strings.Count(dot, "->") != 6 {
+		t.Errorf("WriteModuleDOT wrote an unexpected graph:\n%s", dot)
+	}
+}
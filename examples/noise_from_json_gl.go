@@ -24,7 +24,8 @@ Basic build instructions are:
 	./noise_from_json_gl
 
 Hit `esc` to quit the program.
-Hit `r` to reload the JSON file and compute the noise again!
+The JSON file is watched for changes and the noise is computed again
+whenever it gets saved. Hit `r` to force a reload.
 Hit `c` to toggle the colorize effect.
 
 */
@@ -35,7 +36,6 @@ import (
 	glfw "github.com/go-gl/glfw/v3.1/glfw"
 	mgl "github.com/go-gl/mathgl/mgl32"
 	"github.com/tbogdala/noisey"
-	"io/ioutil"
 	"math"
 	"math/rand"
 )
@@ -43,6 +43,8 @@ import (
 var (
 	configFilename                    = "noise.json"
 	noiseBank       *noisey.NoiseJSON = nil
+	noiseWatcher    *noisey.NoiseJSONWatcher
+	lastWatchError  string
 	noiseTex        uint32
 	colorizeEnabled bool = true
 	imageSize            = int32(512)
@@ -58,9 +60,7 @@ func keyCallback(w *glfw.Window, key glfw.Key, scancode int, action glfw.Action,
 	if key == glfw.KeyR && action == glfw.Press {
 		fmt.Println("Reloading noise bank from JSON file...")
 		loadJSONFile()
-		randomPixels := generateNoiseImage(imageSize)
-		gl.BindTexture(gl.TEXTURE_2D, noiseTex)
-		gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGB, imageSize, imageSize, 0, gl.RGB, gl.UNSIGNED_BYTE, gl.Ptr(randomPixels))
+		updateNoiseTexture()
 	}
 	if key == glfw.KeyC && action == glfw.Press {
 		colorizeEnabled = !colorizeEnabled
@@ -69,13 +69,17 @@ func keyCallback(w *glfw.Window, key glfw.Key, scancode int, action glfw.Action,
 		} else {
 			fmt.Println("Displaying noise as a grayscale image ...")
 		}
-		randomPixels := generateNoiseImage(imageSize)
-		gl.BindTexture(gl.TEXTURE_2D, noiseTex)
-		gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGB, imageSize, imageSize, 0, gl.RGB, gl.UNSIGNED_BYTE, gl.Ptr(randomPixels))
-
+		updateNoiseTexture()
 	}
 }
 
+// updateNoiseTexture regenerates the noise image and uploads it to noiseTex
+func updateNoiseTexture() {
+	randomPixels := generateNoiseImage(imageSize)
+	gl.BindTexture(gl.TEXTURE_2D, noiseTex)
+	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGB, imageSize, imageSize, 0, gl.RGB, gl.UNSIGNED_BYTE, gl.Ptr(randomPixels))
+}
+
 // createTextureFromRGB makes an OpenGL texture and buffers the RGB data into it
 func createTextureFromRGB(rgb []byte, imageSize int32) (tex uint32) {
 	gl.GenTextures(1, &tex)
@@ -155,34 +159,44 @@ func generateNoiseImage(imageSize int32) []byte {
 }
 
 func loadJSONFile() {
-	// load the actual JSON configuration file
-	fmt.Printf("Loading JSON configuration file bytes...\n")
-	bytes, err := ioutil.ReadFile(configFilename)
-	if err != nil {
-		panic(err)
-	}
-
-	fmt.Printf("Parsing the JSON ...\n")
-	noiseBank, err = noisey.LoadNoiseJSON(bytes)
+	// load the actual JSON configuration file and build the sources and
+	// generators from it; the watcher will keep it up to date from here on
+	fmt.Printf("Loading JSON configuration file ...\n")
+	var err error
+	noiseWatcher, err = noisey.NewNoiseJSONWatcher(configFilename, func(s int64) noisey.RandomSource {
+		return rand.New(rand.NewSource(int64(s)))
+	})
 	if err != nil {
 		panic(err)
 	}
+	noiseBank = noiseWatcher.Bank()
 	fmt.Printf("Parsing complete!\n")
+}
 
-	// build the sources from the JSON file
-	err = noiseBank.BuildSources(func(s int64) noisey.RandomSource {
-		return rand.New(rand.NewSource(int64(s)))
-	})
+// checkJSONFile polls the watcher and regenerates the texture if the JSON
+// file was changed. This is called from the render loop so that the OpenGL
+// calls happen on the main thread.
+func checkJSONFile() {
+	reloaded, err := noiseWatcher.Check()
 	if err != nil {
-		panic(err)
+		// this runs every frame, so only report an error once until it changes
+		if err.Error() != lastWatchError {
+			fmt.Printf("Keeping the previous noise bank: %v\n", err)
+			lastWatchError = err.Error()
+		}
+		return
 	}
-	err = noiseBank.BuildGenerators()
-	if err != nil {
-		panic(err)
+	lastWatchError = ""
+	if reloaded {
+		fmt.Println("Noise bank reloaded from JSON file...")
+		noiseBank = noiseWatcher.Bank()
+		updateNoiseTexture()
 	}
 }
 
 func renderCallback(delta float64) {
+	checkJSONFile()
+
 	gl.Viewport(0, 0, int32(app.Width), int32(app.Height))
 	gl.ClearColor(0.0, 0.0, 0.0, 1.0)
 	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
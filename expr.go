@@ -0,0 +1,353 @@
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+/*
+
+This module implements the named parameters and simple arithmetic expressions
+that can be used in place of numbers in NoiseJSON configuration files.
+
+A sample JSON file using them would contain something like this:
+
+{
+  "Params": {
+    "baseFreq": 1.1,
+    "octaves": 4
+  },
+  ...
+  "Generators": [
+    {
+      "Name": "hifreq",
+      "GeneratorType": "fBm2d",
+      "Sources": [ "os2d" ],
+      "Octaves": "$octaves + 1",
+      "Persistence": 0.75,
+      "Lacunarity": 2.1,
+      "Frequency": "$baseFreq * 2"
+    }
+  ]
+}
+
+Expressions support numbers, $name references to Params, the + - * / operators,
+unary minus and parentheses. They are evaluated when BuildSources() and
+BuildGenerators() are called, so changing NoiseJSON.Params (or calling SetParam())
+and building again retunes every generator referencing those parameters.
+Integer fields, like Octaves, get the expression result rounded to the nearest
+integer, with halves rounded up: 2.5 becomes 3 and -2.5 becomes -2.
+
+*/
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// SetParam sets the value of a named parameter used by expressions in
+// the configuration. The new value takes effect on the next build.
+func (cfg *NoiseJSON) SetParam(name string, value float64) {
+	if cfg.Params == nil {
+		cfg.Params = make(map[string]float64)
+	}
+	cfg.Params[name] = value
+}
+
+// evalExpression evaluates the arithmetic expression using params to
+// resolve any $name references.
+func evalExpression(expr string, params map[string]float64) (float64, error) {
+	p := exprParser{input: expr, params: params}
+	v, err := p.parseSum()
+	if err != nil {
+		return 0, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.input) {
+		return 0, fmt.Errorf("unexpected '%c' at position %d in expression \"%s\"", p.input[p.pos], p.pos, expr)
+	}
+	return v, nil
+}
+
+// exprParser is a small recursive descent parser that evaluates as it parses.
+type exprParser struct {
+	input  string
+	pos    int
+	params map[string]float64
+}
+
+func (p *exprParser) skipSpace() {
+	for p.pos < len(p.input) && (p.input[p.pos] == ' ' || p.input[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// parseSum handles: product (('+' | '-') product)*
+func (p *exprParser) parseSum() (float64, error) {
+	v, err := p.parseProduct()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.input) || (p.input[p.pos] != '+' && p.input[p.pos] != '-') {
+			return v, nil
+		}
+		op := p.input[p.pos]
+		p.pos++
+		rhs, err := p.parseProduct()
+		if err != nil {
+			return 0, err
+		}
+		if op == '+' {
+			v += rhs
+		} else {
+			v -= rhs
+		}
+	}
+}
+
+// parseProduct handles: unary (('*' | '/') unary)*
+func (p *exprParser) parseProduct() (float64, error) {
+	v, err := p.parseUnary()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.input) || (p.input[p.pos] != '*' && p.input[p.pos] != '/') {
+			return v, nil
+		}
+		op := p.input[p.pos]
+		p.pos++
+		rhs, err := p.parseUnary()
+		if err != nil {
+			return 0, err
+		}
+		if op == '*' {
+			v *= rhs
+		} else {
+			if rhs == 0 {
+				return 0, fmt.Errorf("division by zero in expression \"%s\"", p.input)
+			}
+			v /= rhs
+		}
+	}
+}
+
+// parseUnary handles: '-' unary | '+' unary | primary
+func (p *exprParser) parseUnary() (float64, error) {
+	p.skipSpace()
+	if p.pos < len(p.input) && (p.input[p.pos] == '-' || p.input[p.pos] == '+') {
+		op := p.input[p.pos]
+		p.pos++
+		v, err := p.parseUnary()
+		if op == '-' {
+			v = -v
+		}
+		return v, err
+	}
+	return p.parsePrimary()
+}
+
+// parsePrimary handles: number | '$' name | '(' sum ')'
+func (p *exprParser) parsePrimary() (float64, error) {
+	p.skipSpace()
+	if p.pos >= len(p.input) {
+		return 0, fmt.Errorf("unexpected end of expression \"%s\"", p.input)
+	}
+
+	c := p.input[p.pos]
+	switch {
+	case c == '(':
+		p.pos++
+		v, err := p.parseSum()
+		if err != nil {
+			return 0, err
+		}
+		p.skipSpace()
+		if p.pos >= len(p.input) || p.input[p.pos] != ')' {
+			return 0, fmt.Errorf("missing ')' in expression \"%s\"", p.input)
+		}
+		p.pos++
+		return v, nil
+
+	case c == '$':
+		p.pos++
+		start := p.pos
+		for p.pos < len(p.input) && isExprNameChar(p.input[p.pos]) {
+			p.pos++
+		}
+		name := p.input[start:p.pos]
+		if name == "" {
+			return 0, fmt.Errorf("missing parameter name after '$' in expression \"%s\"", p.input)
+		}
+		v, ok := p.params[name]
+		if !ok {
+			return 0, fmt.Errorf("undefined parameter \"%s\" in expression \"%s\"", name, p.input)
+		}
+		return v, nil
+
+	case (c >= '0' && c <= '9') || c == '.':
+		start := p.pos
+		for p.pos < len(p.input) && ((p.input[p.pos] >= '0' && p.input[p.pos] <= '9') || p.input[p.pos] == '.') {
+			p.pos++
+		}
+		// allow an exponent like 1e-3
+		if p.pos < len(p.input) && (p.input[p.pos] == 'e' || p.input[p.pos] == 'E') {
+			p.pos++
+			if p.pos < len(p.input) && (p.input[p.pos] == '-' || p.input[p.pos] == '+') {
+				p.pos++
+			}
+			for p.pos < len(p.input) && p.input[p.pos] >= '0' && p.input[p.pos] <= '9' {
+				p.pos++
+			}
+		}
+		v, err := strconv.ParseFloat(p.input[start:p.pos], 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number \"%s\" in expression \"%s\"", p.input[start:p.pos], p.input)
+		}
+		return v, nil
+	}
+
+	return 0, fmt.Errorf("unexpected '%c' at position %d in expression \"%s\"", c, p.pos, p.input)
+}
+
+func isExprNameChar(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// numericField returns the name of the exported numeric field in the struct
+// type t that matches the JSON key, using the same case insensitive matching
+// that encoding/json does.
+func numericField(t reflect.Type, key string) (string, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" || !strings.EqualFold(f.Name, key) {
+			continue
+		}
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		switch ft.Kind() {
+		case reflect.Int, reflect.Int64, reflect.Float64:
+			return f.Name, true
+		}
+	}
+	return "", false
+}
+
+// unmarshalWithExpressions unmarshals the JSON object in data into the struct
+// pointed to by v. Numeric fields given as strings are not unmarshalled but
+// stored in exprs by field name so that they can be evaluated at build time;
+// numeric fields given as numbers remove any expression previously stored.
+func unmarshalWithExpressions(data []byte, v interface{}, exprs *map[string]string) error {
+	var fields map[string]json.RawMessage
+	err := json.Unmarshal(data, &fields)
+	if err != nil {
+		return err
+	}
+
+	t := reflect.ValueOf(v).Elem().Type()
+	for key, raw := range fields {
+		name, ok := numericField(t, key)
+		if !ok {
+			continue
+		}
+		trimmed := bytes.TrimSpace(raw)
+		if len(trimmed) > 0 && trimmed[0] == '"' {
+			var expr string
+			err = json.Unmarshal(trimmed, &expr)
+			if err != nil {
+				return err
+			}
+			if *exprs == nil {
+				*exprs = make(map[string]string)
+			}
+			(*exprs)[name] = expr
+			delete(fields, key)
+		} else if *exprs != nil {
+			delete(*exprs, name)
+		}
+	}
+
+	rest, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(rest, v)
+}
+
+// marshalWithExpressions marshals the struct v to JSON, writing the
+// expressions in place of the numeric field values they are bound to.
+// The fields are written in the order they are declared in the struct.
+func marshalWithExpressions(v interface{}, exprs map[string]string) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || len(exprs) == 0 {
+		return data, err
+	}
+
+	var fields map[string]json.RawMessage
+	err = json.Unmarshal(data, &fields)
+	if err != nil {
+		return nil, err
+	}
+	for name, expr := range exprs {
+		raw, err := json.Marshal(expr)
+		if err != nil {
+			return nil, err
+		}
+		fields[name] = raw
+	}
+
+	var b bytes.Buffer
+	b.WriteByte('{')
+	t := reflect.TypeOf(v)
+	for i := 0; i < t.NumField(); i++ {
+		raw, ok := fields[t.Field(i).Name]
+		if !ok {
+			continue
+		}
+		if b.Len() > 1 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(t.Field(i).Name)
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(raw)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// applyExpressions evaluates the expressions and sets the numeric fields
+// of the struct pointed to by v with the results. Pointer fields are set to
+// a newly allocated value.
+func applyExpressions(v interface{}, exprs map[string]string, params map[string]float64) error {
+	rv := reflect.ValueOf(v).Elem()
+	for name, expr := range exprs {
+		result, err := evalExpression(expr, params)
+		if err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+		f := rv.FieldByName(name)
+		if f.Kind() == reflect.Ptr {
+			// optional fields like SourceJSON.Quality get set when bound
+			f.Set(reflect.New(f.Type().Elem()))
+			f = f.Elem()
+		}
+		switch f.Kind() {
+		case reflect.Int, reflect.Int64:
+			f.SetInt(int64(math.Floor(result + 0.5)))
+		case reflect.Float64:
+			f.SetFloat(result)
+		default:
+			return fmt.Errorf("%s is not a numeric field", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestEvalExpression(t *testing.T) {
+	params := map[string]float64{"a": 2, "baseFreq": 1.5, "x_1": -4}
+	tests := []struct {
+		expr string
+		want float64
+	}{
+		{"42", 42},
+		{"1.25", 1.25},
+		{"1e-3", 0.001},
+		{"1 + 2 * 3", 7},
+		{"8 - 4 - 2", 2},
+		{"8 / 4 / 2", 1},
+		{"2 * 3 + 4 * 5", 26},
+		{"1 + 6 / 3 * 2", 5},
+		{"(1 + 2) * 3", 9},
+		{"2 * (3 + 4) * 5", 70},
+		{"((1))", 1},
+		{"-2", -2},
+		{"-2 * -3", 6},
+		{"--1", 1},
+		{"-(1 + 2)", -3},
+		{"4 - -2", 6},
+		{"$a", 2},
+		{"$baseFreq * 2", 3},
+		{"-$x_1 + $a", 6},
+		{"  $a*$a  ", 4},
+	}
+	for _, test := range tests {
+		got, err := evalExpression(test.expr, params)
+		if err != nil {
+			t.Errorf("%q returned an error: %v", test.expr, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("%q = %v, expected %v", test.expr, got, test.want)
+		}
+	}
+}
+
+func TestEvalExpressionErrors(t *testing.T) {
+	params := map[string]float64{"a": 2, "zero": 0}
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"$b", "undefined parameter \"b\""},
+		{"$a + $missing", "undefined parameter \"missing\""},
+		{"1 / 0", "division by zero"},
+		{"$a / $zero", "division by zero"},
+		{"1 / (1 - 1)", "division by zero"},
+		{"1 2", "unexpected '2'"},
+		{"1 )", "unexpected ')'"},
+		{"$a $a", "unexpected '$'"},
+		{"(1 + 2", "missing ')'"},
+		{"1 +", "unexpected end of expression"},
+		{"", "unexpected end of expression"},
+		{"$", "missing parameter name"},
+		{"* 2", "unexpected '*'"},
+	}
+	for _, test := range tests {
+		got, err := evalExpression(test.expr, params)
+		if err == nil {
+			t.Errorf("%q = %v, expected an error containing %q", test.expr, got, test.want)
+			continue
+		}
+		if !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%q returned the error %q, expected it to contain %q", test.expr, err, test.want)
+		}
+	}
+}
+
+func TestApplyExpressionsRounding(t *testing.T) {
+	tests := []struct {
+		expr string
+		want int
+	}{
+		{"2", 2},
+		{"2.4", 2},
+		{"2.5", 3},
+		{"2.6", 3},
+		{"-2.4", -2},
+		{"-2.5", -2}, // halves round up, toward +Inf
+		{"-2.6", -3},
+		{"$octaves / 2", 3},
+	}
+	params := map[string]float64{"octaves": 5}
+	for _, test := range tests {
+		var gen GeneratorJSON
+		err := applyExpressions(&gen, map[string]string{"Octaves": test.expr}, params)
+		if err != nil {
+			t.Errorf("%q returned an error: %v", test.expr, err)
+			continue
+		}
+		if gen.Octaves != test.want {
+			t.Errorf("%q set Octaves to %d, expected %d", test.expr, gen.Octaves, test.want)
+		}
+	}
+
+	var gen GeneratorJSON
+	err := applyExpressions(&gen, map[string]string{"Octaves": "$nope"}, params)
+	if err == nil || !strings.Contains(err.Error(), "Octaves") {
+		t.Errorf("expected the error to name the field, got %v", err)
+	}
+}
+
+func TestExpressionJSONRoundTrip(t *testing.T) {
+	data := `{
+    "Name": "hifreq",
+    "GeneratorType": "fBm2d",
+    "Sources": [ "os2d" ],
+    "Octaves": "$octaves + 1",
+    "Persistence": 0.75,
+    "Lacunarity": 2.1,
+    "Frequency": "$baseFreq * 2"
+  }`
+
+	var gen GeneratorJSON
+	if err := json.Unmarshal([]byte(data), &gen); err != nil {
+		t.Fatalf("Failed to unmarshal the generator: %v", err)
+	}
+	if gen.Expressions["Octaves"] != "$octaves + 1" || gen.Expressions["Frequency"] != "$baseFreq * 2" {
+		t.Fatalf("Expressions weren't kept on unmarshal: %v", gen.Expressions)
+	}
+	if gen.Persistence != 0.75 || gen.Lacunarity != 2.1 {
+		t.Errorf("Plain numbers weren't read: %v %v", gen.Persistence, gen.Lacunarity)
+	}
+
+	out, err := json.Marshal(gen)
+	if err != nil {
+		t.Fatalf("Failed to marshal the generator: %v", err)
+	}
+	var again GeneratorJSON
+	if err := json.Unmarshal(out, &again); err != nil {
+		t.Fatalf("Failed to unmarshal the marshaled generator %s: %v", out, err)
+	}
+	if len(again.Expressions) != 2 ||
+		again.Expressions["Octaves"] != "$octaves + 1" ||
+		again.Expressions["Frequency"] != "$baseFreq * 2" {
+		t.Errorf("Expressions weren't kept on the round trip: %s", out)
+	}
+	if again.Persistence != 0.75 || again.Lacunarity != 2.1 || again.Name != "hifreq" {
+		t.Errorf("Fields changed on the round trip: %s", out)
+	}
+
+	params := map[string]float64{"octaves": 4, "baseFreq": 1.1}
+	if err := applyExpressions(&again, again.Expressions, params); err != nil {
+		t.Fatalf("Failed to apply the expressions: %v", err)
+	}
+	if again.Octaves != 5 || again.Frequency != 2.2 {
+		t.Errorf("Expressions evaluated to Octaves %d and Frequency %v, expected 5 and 2.2", again.Octaves, again.Frequency)
+	}
+}
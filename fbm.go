@@ -8,6 +8,9 @@ See the LICENSE file for more details. */
 This module performs fractal Brownian motion which combines mulitple steps
 of a coherent noise generator, each with different frequency and amplitude.
 
+If the noise source is periodic (see periodic.go) and Lacunarity is an integer,
+the fBm values repeat with a period of the source's period divided by Frequency.
+
 Reference material:
 * Overview: https://code.google.com/p/fractalterraingeneration/wiki/Fractional_Brownian_Motion
 * Libnoise's glossary: http://libnoise.sourceforge.net/glossary/
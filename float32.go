@@ -0,0 +1,1107 @@
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+/*
+
+This module contains float32 versions of the most commonly used sources,
+the fBm generators and Builder2D for real-time clients that work in float32
+anyway, like code uploading the noise to an OpenGL texture. They skip the
+conversions at both ends and keep their tables in float32.
+
+A quick sample of what this looks like is here:
+
+  perlin := noisey.NewPerlinGenerator32Seed(1)
+  fbm := noisey.NewFBMGenerator2D32(&perlin, 4, 0.5, 2.0, 1.0)
+  builder := noisey.NewBuilder2D32(&fbm, 256, 256)
+  builder.Bounds = noisey.Builder2DBounds32{0.0, 0.0, 4.0, 4.0}
+  builder.Build()
+
+The float32 sources use the same permutation tables as their float64 versions
+for a given seed, so the noise looks the same apart from the rounding. The
+64 bit only features (FullHash, Period and the split coordinate methods)
+are not available since float32 coordinates lose precision quickly anyway.
+
+*/
+
+import "math"
+
+// NoiseyGet2D32 is an interface defining how the float32 module types get noise from a source.
+type NoiseyGet2D32 interface {
+	Get2D32(float32, float32) float32
+}
+
+// NoiseyGet3D32 is an interface defining how the float32 module types get noise from a source.
+type NoiseyGet3D32 interface {
+	Get3D32(float32, float32, float32) float32
+}
+
+// Vec4f32 is a simple 4D vector of 32 bit floats
+type Vec4f32 struct {
+	X, Y, Z, W float32
+}
+
+// floor32 returns the largest integer less than or equal to v.
+func floor32(v float32) int {
+	i := int(v)
+	if float32(i) > v {
+		i--
+	}
+	return i
+}
+
+func lerp32(a, b, v float32) float32 {
+	return a*(1-v) + b*v
+}
+
+// sCurve32 is the float32 version of NoiseQuality.sCurve().
+func (q NoiseQuality) sCurve32(v float32) float32 {
+	switch q {
+	case QualityFast:
+		return v
+	case QualityStandard:
+		return v * v * (3 - 2*v)
+	default:
+		return v * v * v * (v*(v*6-15) + 10)
+	}
+}
+
+/* ------------------------------------------------------------------------- */
+
+// PerlinGenerator32 stores the state information for generating float32 perlin noise.
+type PerlinGenerator32 struct {
+	Permutations    []int        // the random permutation table
+	RandomGradients []Vec4f32    // the random gradient table
+	Quality         NoiseQuality // the interpolation used between lattice points
+}
+
+// NewPerlinGenerator32 creates a new state object for the float32 perlin noise generator.
+func NewPerlinGenerator32(rng RandomSource) (pg PerlinGenerator32) {
+	pg.Permutations = rng.Perm(tableSize)
+	pg.RandomGradients = newPerlinGradients32()
+	return
+}
+
+// NewPerlinGenerator32Seed creates a new state object for the float32 perlin noise
+// generator whose permutation table is built from seed, matching NewPerlinGeneratorSeed().
+func NewPerlinGenerator32Seed(seed int64) (pg PerlinGenerator32) {
+	pg.Permutations = NewPCG32(seed).Perm(tableSize)
+	pg.RandomGradients = newPerlinGradients32()
+	return
+}
+
+// newPerlinGradients32 converts the PerlinGenerator gradient table to float32.
+func newPerlinGradients32() []Vec4f32 {
+	gradients := newPerlinGradients()
+	gradients32 := make([]Vec4f32, len(gradients))
+	for i, g := range gradients {
+		gradients32[i] = Vec4f32{float32(g.X), float32(g.Y), float32(g.Z), float32(g.W)}
+	}
+	return gradients32
+}
+
+func (pg *PerlinGenerator32) getGradient2(x, y int) *Vec4f32 {
+	xv := pg.Permutations[x&0xFF]
+	yv := pg.Permutations[xv^(y&0xFF)]
+	return &pg.RandomGradients[yv%32]
+}
+
+func (pg *PerlinGenerator32) getGradient3(x, y, z int) *Vec4f32 {
+	xv := pg.Permutations[x&0xFF]
+	yv := pg.Permutations[xv^(y&0xFF)]
+	zv := pg.Permutations[yv^(z&0xFF)]
+	return &pg.RandomGradients[zv%32]
+}
+
+// Get2D32 calculates the perlin noise at a given 2D coordinate
+func (pg *PerlinGenerator32) Get2D32(x, y float32) float32 {
+	x0 := floor32(x)
+	y0 := floor32(y)
+	fx0 := x - float32(x0)
+	fy0 := y - float32(y0)
+	fx1 := fx0 - 1
+	fy1 := fy0 - 1
+
+	if pg.Quality != QualityAttenuated {
+		g00 := pg.getGradient2(x0, y0)
+		g10 := pg.getGradient2(x0+1, y0)
+		g01 := pg.getGradient2(x0, y0+1)
+		g11 := pg.getGradient2(x0+1, y0+1)
+		f00 := fx0*g00.X + fy0*g00.Y
+		f10 := fx1*g10.X + fy0*g10.Y
+		f01 := fx0*g01.X + fy1*g01.Y
+		f11 := fx1*g11.X + fy1*g11.Y
+
+		sx := pg.Quality.sCurve32(fx0)
+		sy := pg.Quality.sCurve32(fy0)
+		return lerp32(lerp32(f00, f10, sx), lerp32(f01, f11, sx), sy)
+	}
+
+	gradient2 := func(wx, wy int, fx, fy float32) float32 {
+		attn := 1.0 - (fx*fx + fy*fy)
+		if attn > 0.0 {
+			g := pg.getGradient2(wx, wy)
+			return (attn * attn) * (fx*g.X + fy*g.Y)
+		}
+		return 0.0
+	}
+
+	f00 := gradient2(x0, y0, fx0, fy0)
+	f10 := gradient2(x0+1, y0, fx1, fy0)
+	f01 := gradient2(x0, y0+1, fx0, fy1)
+	f11 := gradient2(x0+1, y0+1, fx1, fy1)
+
+	// Arbitrary values to shift and scale noise to -1..1
+	return (f00 + f10 + f01 + f11 + 0.053179) * 1.056165
+}
+
+// Get3D32 calculates the perlin noise at a given 3D coordinate
+func (pg *PerlinGenerator32) Get3D32(x, y, z float32) float32 {
+	x0 := floor32(x)
+	y0 := floor32(y)
+	z0 := floor32(z)
+	fx0 := x - float32(x0)
+	fy0 := y - float32(y0)
+	fz0 := z - float32(z0)
+	fx1 := fx0 - 1
+	fy1 := fy0 - 1
+	fz1 := fz0 - 1
+
+	if pg.Quality != QualityAttenuated {
+		dot := func(wx, wy, wz int, fx, fy, fz float32) float32 {
+			g := pg.getGradient3(wx, wy, wz)
+			return fx*g.X + fy*g.Y + fz*g.Z
+		}
+		f000 := dot(x0, y0, z0, fx0, fy0, fz0)
+		f100 := dot(x0+1, y0, z0, fx1, fy0, fz0)
+		f010 := dot(x0, y0+1, z0, fx0, fy1, fz0)
+		f110 := dot(x0+1, y0+1, z0, fx1, fy1, fz0)
+		f001 := dot(x0, y0, z0+1, fx0, fy0, fz1)
+		f101 := dot(x0+1, y0, z0+1, fx1, fy0, fz1)
+		f011 := dot(x0, y0+1, z0+1, fx0, fy1, fz1)
+		f111 := dot(x0+1, y0+1, z0+1, fx1, fy1, fz1)
+
+		sx := pg.Quality.sCurve32(fx0)
+		sy := pg.Quality.sCurve32(fy0)
+		sz := pg.Quality.sCurve32(fz0)
+		v0 := lerp32(lerp32(f000, f100, sx), lerp32(f010, f110, sx), sy)
+		v1 := lerp32(lerp32(f001, f101, sx), lerp32(f011, f111, sx), sy)
+		return lerp32(v0, v1, sz)
+	}
+
+	gradient3 := func(wx, wy, wz int, fx, fy, fz float32) float32 {
+		attn := 1.0 - (fx*fx + fy*fy + fz*fz)
+		if attn > 0.0 {
+			g := pg.getGradient3(wx, wy, wz)
+			return (attn * attn) * (fx*g.X + fy*g.Y + fz*g.Z)
+		}
+		return 0.0
+	}
+
+	f000 := gradient3(x0, y0, z0, fx0, fy0, fz0)
+	f100 := gradient3(x0+1, y0, z0, fx1, fy0, fz0)
+	f010 := gradient3(x0, y0+1, z0, fx0, fy1, fz0)
+	f110 := gradient3(x0+1, y0+1, z0, fx1, fy1, fz0)
+	f001 := gradient3(x0, y0, z0+1, fx0, fy0, fz1)
+	f101 := gradient3(x0+1, y0, z0+1, fx1, fy0, fz1)
+	f011 := gradient3(x0, y0+1, z0+1, fx0, fy1, fz1)
+	f111 := gradient3(x0+1, y0+1, z0+1, fx1, fy1, fz1)
+
+	// Arbitrary values to shift and scale noise to -1..1
+	return (f000 + f100 + f010 + f110 + f001 + f101 + f011 + f111 + 0.053179) * 1.056165
+}
+
+/* ------------------------------------------------------------------------- */
+
+// OpenSimplexGenerator32 stores the state information for generating float32 opensimplex noise.
+type OpenSimplexGenerator32 struct {
+	Permutations    []int // the random permutation table
+	PermGradIndex3D []int
+}
+
+// NewOpenSimplexGenerator32 creates a new state object for the float32 open simplex noise generator
+func NewOpenSimplexGenerator32(rng RandomSource) (osg OpenSimplexGenerator32) {
+	osg.setPermutations(rng.Perm(permTableSize))
+	return
+}
+
+// NewOpenSimplexGenerator32Seed creates a new state object for the float32 open simplex noise
+// generator whose permutation table is built from seed, matching NewOpenSimplexGeneratorSeed().
+func NewOpenSimplexGenerator32Seed(seed int64) (osg OpenSimplexGenerator32) {
+	osg.setPermutations(NewPCG32(seed).Perm(permTableSize))
+	return
+}
+
+// setPermutations stores the permutation table and constructs the gradient
+// index table from it, the same way OpenSimplexGenerator does.
+func (osg *OpenSimplexGenerator32) setPermutations(perm []int) {
+	osg.Permutations = perm
+	osg.PermGradIndex3D = make([]int, permTableSize)
+	gradLengthDiv3 := len(gradients3D) / 3
+	for i := range osg.PermGradIndex3D {
+		osg.PermGradIndex3D[i] = (osg.Permutations[i] % gradLengthDiv3) * 3
+	}
+}
+
+func (osg *OpenSimplexGenerator32) extrapolate2(xsb int, ysb int, dx float32, dy float32) float32 {
+	index := osg.Permutations[(osg.Permutations[xsb&0xFF]+ysb)&0xFF] & 0x0E
+	return float32(gradients2D[index])*dx + float32(gradients2D[index+1])*dy
+}
+
+// Get2D32 calculates the noise at a given 2D coordinate. See
+// OpenSimplexGenerator.getSuperCell2D() for the comments on the algorithm.
+func (osg *OpenSimplexGenerator32) Get2D32(x float32, y float32) float32 {
+	const stretch = float32(stretchConstant2D)
+	const squish = float32(squishConstant2D)
+
+	stretchOffset := (x + y) * stretch
+	xs := x + stretchOffset
+	ys := y + stretchOffset
+
+	xsb := floor32(xs)
+	ysb := floor32(ys)
+
+	squishOffset := float32(xsb+ysb) * squish
+	xb := float32(xsb) + squishOffset
+	yb := float32(ysb) + squishOffset
+
+	xins := xs - float32(xsb)
+	yins := ys - float32(ysb)
+	inSum := xins + yins
+
+	dx0 := x - xb
+	dy0 := y - yb
+
+	var dx_ext, dy_ext float32
+	var xsv_ext, ysv_ext int
+	var value float32
+
+	// contribution (1,0)
+	dx1 := dx0 - 1 - squish
+	dy1 := dy0 - 0 - squish
+	attn1 := 2 - dx1*dx1 - dy1*dy1
+	if attn1 > 0 {
+		attn1 *= attn1
+		value += attn1 * attn1 * osg.extrapolate2(xsb+1, ysb, dx1, dy1)
+	}
+
+	// contribution (0,1)
+	dx2 := dx0 - 0 - squish
+	dy2 := dy0 - 1 - squish
+	attn2 := 2 - dx2*dx2 - dy2*dy2
+	if attn2 > 0 {
+		attn2 *= attn2
+		value += attn2 * attn2 * osg.extrapolate2(xsb, ysb+1, dx2, dy2)
+	}
+
+	if inSum <= 1 { // we're inside the triangle (2-Simplex) at (0,0)
+		zins := 1 - inSum
+		if (zins > xins) || (zins > yins) { // (0,0) is one of the closest two triangle vertices
+			if xins > yins {
+				xsv_ext = xsb + 1
+				ysv_ext = ysb - 1
+				dx_ext = dx0 - 1
+				dy_ext = dy0 + 1
+			} else {
+				xsv_ext = xsb - 1
+				ysv_ext = ysb + 1
+				dx_ext = dx0 + 1
+				dy_ext = dy0 - 1
+			}
+		} else { // (1,0) and (0,1) are the closest two vertices
+			xsv_ext = xsb + 1
+			ysv_ext = ysb + 1
+			dx_ext = dx0 - 1 - 2*squish
+			dy_ext = dy0 - 1 - 2*squish
+		}
+	} else { // we're inside the triangle (2-Simplex) at (1,1)
+		zins := 2 - inSum
+		if (zins < xins) || (zins < yins) { // (0,0) is one of the closest two triangle vertices
+			if xins > yins {
+				xsv_ext = xsb + 2
+				ysv_ext = ysb
+				dx_ext = dx0 - 2 - 2*squish
+				dy_ext = dy0 - 2*squish
+			} else {
+				xsv_ext = xsb
+				ysv_ext = ysb + 2
+				dx_ext = dx0 - 2*squish
+				dy_ext = dy0 - 2 - 2*squish
+			}
+		} else { // (1,0) and (0,1) are the closest two vertices
+			dx_ext = dx0
+			dy_ext = dy0
+			xsv_ext = xsb
+			ysv_ext = ysb
+		}
+		xsb += 1
+		ysb += 1
+		dx0 = dx0 - 1 - 2*squish
+		dy0 = dy0 - 1 - 2*squish
+	}
+
+	// contribution (0,0) or (1,1)
+	attn0 := 2 - dx0*dx0 - dy0*dy0
+	if attn0 > 0 {
+		attn0 *= attn0
+		value += attn0 * attn0 * osg.extrapolate2(xsb, ysb, dx0, dy0)
+	}
+
+	// extra vertex
+	attn_ext := 2 - dx_ext*dx_ext - dy_ext*dy_ext
+	if attn_ext > 0 {
+		attn_ext *= attn_ext
+		value += attn_ext * attn_ext * osg.extrapolate2(xsv_ext, ysv_ext, dx_ext, dy_ext)
+	}
+
+	return value / normConstant2D
+}
+
+func (osg *OpenSimplexGenerator32) extrapolate3(xsb int, ysb int, zsb int, dx float32, dy float32, dz float32) float32 {
+	px := osg.Permutations[xsb&0xFF]
+	py := osg.Permutations[(px+ysb)&0xFF]
+	index := osg.PermGradIndex3D[(py+zsb)&0xFF]
+	return float32(gradients3D[index])*dx + float32(gradients3D[index+1])*dy + float32(gradients3D[index+2])*dz
+}
+
+// Get3D32 calculates the noise at a given 3D coordinate. See
+// OpenSimplexGenerator.getSuperCell3D() for the comments on the algorithm.
+func (osg *OpenSimplexGenerator32) Get3D32(x float32, y float32, z float32) float32 {
+	const stretch = float32(stretchConstant3D)
+	const squish = float32(squishConstant3D)
+
+	stretchOffset := (x + y + z) * stretch
+	xs := x + stretchOffset
+	ys := y + stretchOffset
+	zs := z + stretchOffset
+
+	xsb := floor32(xs)
+	ysb := floor32(ys)
+	zsb := floor32(zs)
+
+	squishOffset := float32(xsb+ysb+zsb) * squish
+	xb := float32(xsb) + squishOffset
+	yb := float32(ysb) + squishOffset
+	zb := float32(zsb) + squishOffset
+
+	xins := xs - float32(xsb)
+	yins := ys - float32(ysb)
+	zins := zs - float32(zsb)
+
+	dx0 := x - xb
+	dy0 := y - yb
+	dz0 := z - zb
+
+	return osg.getSuperCell3D(xsb, ysb, zsb, xins, yins, zins, dx0, dy0, dz0)
+}
+
+// getSuperCell3D is the float32 version of OpenSimplexGenerator.getSuperCell3D().
+func (osg *OpenSimplexGenerator32) getSuperCell3D(xsb, ysb, zsb int, xins, yins, zins, dx0, dy0, dz0 float32) float32 {
+	const squish = float32(squishConstant3D)
+
+	// Sum those together to get a value that determines which region we're in.
+	var inSum float32 = xins + yins + zins
+
+	// We'll be defining these inside the next block and using them afterwards.
+	var dx_ext0, dy_ext0, dz_ext0 float32
+	var dx_ext1, dy_ext1, dz_ext1 float32
+	var xsv_ext0, ysv_ext0, zsv_ext0 int
+	var xsv_ext1, ysv_ext1, zsv_ext1 int
+
+	var value float32 = 0.0
+	if inSum <= 1.0 { // We're inside the tetrahedron (3-Simplex) at (0,0,0)
+		// Determine which two of (0,0,1), (0,1,0), (1,0,0) are closest.
+		var aPoint byte = 0x01
+		var aScore float32 = xins
+		var bPoint byte = 0x02
+		var bScore float32 = yins
+		if aScore >= bScore && zins > bScore {
+			bScore = zins
+			bPoint = 0x04
+		} else if aScore < bScore && zins > aScore {
+			aScore = zins
+			aPoint = 0x04
+		}
+
+		// Now we determine the two lattice points not part of the tetrahedron that may contribute.
+		// This depends on the closest two tetrahedral vertices, including (0,0,0)
+		var c byte
+		var wins float32 = 1.0 - inSum
+		if wins > aScore || wins > bScore { // (0,0,0) is one of the closest two tetrahedral vertices.
+			// Our other closest vertex is the closest out of a and b.
+			if bScore > aScore {
+				c = bPoint
+			} else {
+				c = aPoint
+			}
+
+			if c&0x01 == 0 {
+				xsv_ext0 = xsb - 1
+				xsv_ext1 = xsb
+				dx_ext0 = dx0 + 1.0
+				dx_ext1 = dx0
+			} else {
+				xsv_ext0 = xsb + 1
+				xsv_ext1 = xsv_ext0
+				dx_ext0 = dx0 - 1.0
+				dx_ext1 = dx_ext0
+			}
+
+			if c&0x02 == 0 {
+				ysv_ext0 = ysb
+				ysv_ext1 = ysb
+				dy_ext0 = dy0
+				dy_ext1 = dy0
+				if c&0x01 == 0 {
+					ysv_ext1 -= 1
+					dy_ext1 += 1.0
+				} else {
+					ysv_ext0 -= 1
+					dy_ext0 += 1.0
+				}
+			} else {
+				ysv_ext0 = ysb + 1
+				ysv_ext1 = ysv_ext0
+				dy_ext0 = dy0 - 1.0
+				dy_ext1 = dy_ext0
+			}
+
+			if c&0x04 == 0 {
+				zsv_ext0 = zsb
+				zsv_ext1 = zsb - 1
+				dz_ext0 = dz0
+				dz_ext1 = dz0 + 1.0
+			} else {
+				zsv_ext0 = zsb + 1
+				zsv_ext1 = zsv_ext0
+				dz_ext0 = dz0 - 1.0
+				dz_ext1 = dz_ext0
+			}
+		} else { // (0,0,0) is not one of the closest two tetrahedral vertices.
+			c = aPoint | bPoint // Our two extra vertices are determined by the closest two.
+
+			if c&0x01 == 0 {
+				xsv_ext0 = xsb
+				xsv_ext1 = xsb - 1
+				dx_ext0 = dx0 - 2*squish
+				dx_ext1 = dx0 + 1 - squish
+			} else {
+				xsv_ext0 = xsb + 1
+				xsv_ext1 = xsv_ext0
+				dx_ext0 = dx0 - 1 - 2*squish
+				dx_ext1 = dx0 - 1 - squish
+			}
+
+			if c&0x02 == 0 {
+				ysv_ext0 = ysb
+				ysv_ext1 = ysb - 1
+				dy_ext0 = dy0 - 2*squish
+				dy_ext1 = dy0 + 1 - squish
+			} else {
+				ysv_ext0 = ysb + 1
+				ysv_ext1 = ysv_ext0
+				dy_ext0 = dy0 - 1 - 2*squish
+				dy_ext1 = dy0 - 1 - squish
+			}
+
+			if c&0x04 == 0 {
+				zsv_ext0 = zsb
+				zsv_ext1 = zsb - 1
+				dz_ext0 = dz0 - 2*squish
+				dz_ext1 = dz0 + 1 - squish
+			} else {
+				zsv_ext0 = zsb + 1
+				zsv_ext1 = zsv_ext0
+				dz_ext0 = dz0 - 1 - 2*squish
+				dz_ext1 = dz0 - 1 - squish
+			}
+		}
+
+		// Contribution (0,0,0)
+		var attn0 float32 = 2 - dx0*dx0 - dy0*dy0 - dz0*dz0
+		if attn0 > 0 {
+			attn0 *= attn0
+			value += attn0 * attn0 * osg.extrapolate3(xsb+0, ysb+0, zsb+0, dx0, dy0, dz0)
+		}
+
+		// Contribution (1,0,0)
+		var dx1 float32 = dx0 - 1 - squish
+		var dy1 float32 = dy0 - 0 - squish
+		var dz1 float32 = dz0 - 0 - squish
+		var attn1 float32 = 2 - dx1*dx1 - dy1*dy1 - dz1*dz1
+		if attn1 > 0 {
+			attn1 *= attn1
+			value += attn1 * attn1 * osg.extrapolate3(xsb+1, ysb+0, zsb+0, dx1, dy1, dz1)
+		}
+
+		// Contribution (0,1,0)
+		var dx2 float32 = dx0 - 0 - squish
+		var dy2 float32 = dy0 - 1 - squish
+		var dz2 float32 = dz1
+		var attn2 float32 = 2 - dx2*dx2 - dy2*dy2 - dz2*dz2
+		if attn2 > 0 {
+			attn2 *= attn2
+			value += attn2 * attn2 * osg.extrapolate3(xsb+0, ysb+1, zsb+0, dx2, dy2, dz2)
+		}
+
+		// Contribution (0,0,1)
+		var dx3 float32 = dx2
+		var dy3 float32 = dy1
+		var dz3 float32 = dz0 - 1 - squish
+		var attn3 float32 = 2 - dx3*dx3 - dy3*dy3 - dz3*dz3
+		if attn3 > 0 {
+			attn3 *= attn3
+			value += attn3 * attn3 * osg.extrapolate3(xsb+0, ysb+0, zsb+1, dx3, dy3, dz3)
+		}
+	} else if inSum >= 2 { // We're inside the tetrahedron (3-Simplex) at (1,1,1)
+		// Determine which two tetrahedral vertices are the closest, out of (1,1,0), (1,0,1), (0,1,1) but not (1,1,1).
+		var aPoint byte = 0x06
+		var aScore float32 = xins
+		var bPoint byte = 0x05
+		var bScore float32 = yins
+		if aScore <= bScore && zins < bScore {
+			bScore = zins
+			bPoint = 0x03
+		} else if aScore > bScore && zins < aScore {
+			aScore = zins
+			aPoint = 0x03
+		}
+
+		// Now we determine the two lattice points not part of the tetrahedron that may contribute.
+		// This depends on the closest two tetrahedral vertices, including (1,1,1)
+		var c byte
+		var wins float32 = 3.0 - inSum
+		if wins < aScore || wins < bScore { // (1,1,1) is one of the closest two tetrahedral vertices.
+			// Our other closest vertex is the closest out of a and b.
+			if bScore < aScore {
+				c = bPoint
+			} else {
+				c = aPoint
+			}
+
+			if c&0x01 != 0 {
+				xsv_ext0 = xsb + 2
+				xsv_ext1 = xsb + 1
+				dx_ext0 = dx0 - 2 - 3*squish
+				dx_ext1 = dx0 - 1 - 3*squish
+			} else {
+				xsv_ext0 = xsb
+				xsv_ext1 = xsb
+				dx_ext0 = dx0 - 3*squish
+				dx_ext1 = dx_ext0
+			}
+
+			if c&0x02 != 0 {
+				ysv_ext0 = ysb + 1
+				ysv_ext1 = ysv_ext0
+				dy_ext0 = dy0 - 1 - 3*squish
+				dy_ext1 = dy_ext0
+				if c&0x01 != 0 {
+					ysv_ext1 += 1
+					dy_ext1 -= 1
+				} else {
+					ysv_ext0 += 1
+					dy_ext0 -= 1
+				}
+			} else {
+				ysv_ext0 = ysb
+				ysv_ext1 = ysb
+				dy_ext0 = dy0 - 3*squish
+				dy_ext1 = dy_ext0
+			}
+
+			if c&0x04 != 0 {
+				zsv_ext0 = zsb + 1
+				zsv_ext1 = zsb + 2
+				dz_ext0 = dz0 - 1 - 3*squish
+				dz_ext1 = dz0 - 2 - 3*squish
+			} else {
+				zsv_ext0 = zsb
+				zsv_ext1 = zsb
+				dz_ext0 = dz0 - 3*squish
+				dz_ext1 = dz_ext0
+			}
+		} else { // (1,1,1) is not one of the closest two tetrahedral vertices.
+			c = aPoint & bPoint // Our two extra vertices are determined by the closest two.
+
+			if c&0x01 != 0 {
+				xsv_ext0 = xsb + 1
+				xsv_ext1 = xsb + 2
+				dx_ext0 = dx0 - 1 - squish
+				dx_ext1 = dx0 - 2 - 2*squish
+			} else {
+				xsv_ext0 = xsb
+				xsv_ext1 = xsb
+				dx_ext0 = dx0 - squish
+				dx_ext1 = dx0 - 2*squish
+			}
+
+			if c&0x02 != 0 {
+				ysv_ext0 = ysb + 1
+				ysv_ext1 = ysb + 2
+				dy_ext0 = dy0 - 1 - squish
+				dy_ext1 = dy0 - 2 - 2*squish
+			} else {
+				ysv_ext0 = ysb
+				ysv_ext1 = ysb
+				dy_ext0 = dy0 - squish
+				dy_ext1 = dy0 - 2*squish
+			}
+
+			if c&0x04 != 0 {
+				zsv_ext0 = zsb + 1
+				zsv_ext1 = zsb + 2
+				dz_ext0 = dz0 - 1 - squish
+				dz_ext1 = dz0 - 2 - 2*squish
+			} else {
+				zsv_ext0 = zsb
+				zsv_ext1 = zsb
+				dz_ext0 = dz0 - squish
+				dz_ext1 = dz0 - 2*squish
+			}
+		}
+
+		// Contribution (1,1,0)
+		var dx3 float32 = dx0 - 1 - 2*squish
+		var dy3 float32 = dy0 - 1 - 2*squish
+		var dz3 float32 = dz0 - 0 - 2*squish
+		var attn3 float32 = 2 - dx3*dx3 - dy3*dy3 - dz3*dz3
+		if attn3 > 0 {
+			attn3 *= attn3
+			value += attn3 * attn3 * osg.extrapolate3(xsb+1, ysb+1, zsb+0, dx3, dy3, dz3)
+		}
+
+		// Contribution (1,0,1)
+		var dx2 float32 = dx3
+		var dy2 float32 = dy0 - 0 - 2*squish
+		var dz2 float32 = dz0 - 1 - 2*squish
+		var attn2 float32 = 2 - dx2*dx2 - dy2*dy2 - dz2*dz2
+		if attn2 > 0 {
+			attn2 *= attn2
+			value += attn2 * attn2 * osg.extrapolate3(xsb+1, ysb+0, zsb+1, dx2, dy2, dz2)
+		}
+
+		//Contribution (0,1,1)
+		var dx1 float32 = dx0 - 0 - 2*squish
+		var dy1 float32 = dy3
+		var dz1 float32 = dz2
+		var attn1 float32 = 2 - dx1*dx1 - dy1*dy1 - dz1*dz1
+		if attn1 > 0 {
+			attn1 *= attn1
+			value += attn1 * attn1 * osg.extrapolate3(xsb+0, ysb+1, zsb+1, dx1, dy1, dz1)
+		}
+
+		//Contribution (1,1,1)
+		dx0 = dx0 - 1 - 3*squish
+		dy0 = dy0 - 1 - 3*squish
+		dz0 = dz0 - 1 - 3*squish
+		var attn0 float32 = 2 - dx0*dx0 - dy0*dy0 - dz0*dz0
+		if attn0 > 0 {
+			attn0 *= attn0
+			value += attn0 * attn0 * osg.extrapolate3(xsb+1, ysb+1, zsb+1, dx0, dy0, dz0)
+		}
+	} else { // We're inside the octahedron (Rectified 3-Simplex) in between.
+		var aScore float32
+		var aPoint byte
+		var aIsFurtherSide bool
+		var bPoint byte
+		var bScore float32
+		var bIsFurtherSide bool
+
+		// Decide between point (0,0,1) and (1,1,0) as closest
+		var p1 float32 = xins + yins
+		if p1 > 1.0 {
+			aScore = p1 - 1
+			aPoint = 0x03
+			aIsFurtherSide = true
+		} else {
+			aScore = 1 - p1
+			aPoint = 0x04
+			aIsFurtherSide = false
+		}
+
+		// Decide between point (0,1,0) and (1,0,1) as closest
+		var p2 float32 = xins + zins
+		if p2 > 1.0 {
+			bScore = p2 - 1
+			bPoint = 0x05
+			bIsFurtherSide = true
+		} else {
+			bScore = 1 - p2
+			bPoint = 0x02
+			bIsFurtherSide = false
+		}
+
+		// The closest out of the two (1,0,0) and (0,1,1) will replace the furthest out of the two decided above, if closer.
+		var p3 float32 = yins + zins
+		if p3 > 1.0 {
+			var score float32 = p3 - 1
+			if aScore <= bScore && aScore < score {
+				aScore = score
+				aPoint = 0x06
+				aIsFurtherSide = true
+			} else if aScore > bScore && bScore < score {
+				bScore = score
+				bPoint = 0x06
+				bIsFurtherSide = true
+			}
+		} else {
+			var score float32 = 1 - p3
+			if aScore <= bScore && aScore < score {
+				aScore = score
+				aPoint = 0x01
+				aIsFurtherSide = false
+			} else if aScore > bScore && bScore < score {
+				bScore = score
+				bPoint = 0x01
+				bIsFurtherSide = false
+			}
+		}
+
+		// Where each of the two closest points are determines how the extra two vertices are calculated.
+		if aIsFurtherSide == bIsFurtherSide {
+			if aIsFurtherSide { // Both closest points on (1,1,1) side
+				// One of the two extra points is (1,1,1)
+				dx_ext0 = dx0 - 1 - 3*squish
+				dy_ext0 = dy0 - 1 - 3*squish
+				dz_ext0 = dz0 - 1 - 3*squish
+				xsv_ext0 = xsb + 1
+				ysv_ext0 = ysb + 1
+				zsv_ext0 = zsb + 1
+
+				// Other extra point is based on the shared axis.
+				var c byte = aPoint & bPoint
+				if c&0x01 != 0 {
+					dx_ext1 = dx0 - 2 - 2*squish
+					dy_ext1 = dy0 - 2*squish
+					dz_ext1 = dz0 - 2*squish
+					xsv_ext1 = xsb + 2
+					ysv_ext1 = ysb
+					zsv_ext1 = zsb
+				} else if c&0x02 != 0 {
+					dx_ext1 = dx0 - 2*squish
+					dy_ext1 = dy0 - 2 - 2*squish
+					dz_ext1 = dz0 - 2*squish
+					xsv_ext1 = xsb
+					ysv_ext1 = ysb + 2
+					zsv_ext1 = zsb
+				} else {
+					dx_ext1 = dx0 - 2*squish
+					dy_ext1 = dy0 - 2*squish
+					dz_ext1 = dz0 - 2 - 2*squish
+					xsv_ext1 = xsb
+					ysv_ext1 = ysb
+					zsv_ext1 = zsb + 2
+				}
+			} else { // Both closest points on (0,0,0) side
+				// one of the two extra points is (0,0,0)
+				dx_ext0 = dx0
+				dy_ext0 = dy0
+				dz_ext0 = dz0
+				xsv_ext0 = xsb
+				ysv_ext0 = ysb
+				zsv_ext0 = zsb
+
+				// Other extra point is based on the omitted axis.
+				var c byte = aPoint | bPoint
+				if c&0x01 == 0 {
+					dx_ext1 = dx0 + 1 - squish
+					dy_ext1 = dy0 - 1 - squish
+					dz_ext1 = dz0 - 1 - squish
+					xsv_ext1 = xsb - 1
+					ysv_ext1 = ysb + 1
+					zsv_ext1 = zsb + 1
+				} else if c&0x02 == 0 {
+					dx_ext1 = dx0 - 1 - squish
+					dy_ext1 = dy0 + 1 - squish
+					dz_ext1 = dz0 - 1 - squish
+					xsv_ext1 = xsb + 1
+					ysv_ext1 = ysb - 1
+					zsv_ext1 = zsb + 1
+				} else {
+					dx_ext1 = dx0 - 1 - squish
+					dy_ext1 = dy0 - 1 - squish
+					dz_ext1 = dz0 + 1 - squish
+					xsv_ext1 = xsb + 1
+					ysv_ext1 = ysb + 1
+					zsv_ext1 = zsb - 1
+				}
+			}
+		} else { // One point on (0,0,0) side, one point on (1,1,1) side
+			var c1, c2 byte
+			if aIsFurtherSide {
+				c1 = aPoint
+				c2 = bPoint
+			} else {
+				c1 = bPoint
+				c2 = aPoint
+			}
+
+			// One contribution is a permutation of (1,1,-1)
+			if c1&0x01 == 0 {
+				dx_ext0 = dx0 + 1 - squish
+				dy_ext0 = dy0 - 1 - squish
+				dz_ext0 = dz0 - 1 - squish
+				xsv_ext0 = xsb - 1
+				ysv_ext0 = ysb + 1
+				zsv_ext0 = zsb + 1
+			} else if c1&0x02 == 0 {
+				dx_ext0 = dx0 - 1 - squish
+				dy_ext0 = dy0 + 1 - squish
+				dz_ext0 = dz0 - 1 - squish
+				xsv_ext0 = xsb + 1
+				ysv_ext0 = ysb - 1
+				zsv_ext0 = zsb + 1
+			} else {
+				dx_ext0 = dx0 - 1 - squish
+				dy_ext0 = dy0 - 1 - squish
+				dz_ext0 = dz0 + 1 - squish
+				xsv_ext0 = xsb + 1
+				ysv_ext0 = ysb + 1
+				zsv_ext0 = zsb - 1
+			}
+
+			// One contribution is a permutation of (0,0,2)
+			dx_ext1 = dx0 - 2*squish
+			dy_ext1 = dy0 - 2*squish
+			dz_ext1 = dz0 - 2*squish
+			xsv_ext1 = xsb
+			ysv_ext1 = ysb
+			zsv_ext1 = zsb
+			if c2&0x01 != 0 {
+				dx_ext1 -= 2
+				xsv_ext1 += 2
+			} else if c2&0x02 != 0 {
+				dy_ext1 -= 2
+				ysv_ext1 += 2
+			} else {
+				dz_ext1 -= 2
+				zsv_ext1 += 2
+			}
+		}
+
+		// Contribution (1,0,0)
+		var dx1 float32 = dx0 - 1 - squish
+		var dy1 float32 = dy0 - 0 - squish
+		var dz1 float32 = dz0 - 0 - squish
+		var attn1 float32 = 2 - dx1*dx1 - dy1*dy1 - dz1*dz1
+		if attn1 > 0 {
+			attn1 *= attn1
+			value += attn1 * attn1 * osg.extrapolate3(xsb+1, ysb+0, zsb+0, dx1, dy1, dz1)
+		}
+
+		// Contribution (0,1,0)
+		var dx2 float32 = dx0 - 0 - squish
+		var dy2 float32 = dy0 - 1 - squish
+		var dz2 float32 = dz1
+		var attn2 float32 = 2 - dx2*dx2 - dy2*dy2 - dz2*dz2
+		if attn2 > 0 {
+			attn2 *= attn2
+			value += attn2 * attn2 * osg.extrapolate3(xsb+0, ysb+1, zsb+0, dx2, dy2, dz2)
+		}
+
+		// Contribution (0,0,1)
+		var dx3 float32 = dx2
+		var dy3 float32 = dy1
+		var dz3 float32 = dz0 - 1 - squish
+		var attn3 float32 = 2 - dx3*dx3 - dy3*dy3 - dz3*dz3
+		if attn3 > 0 {
+			attn3 *= attn3
+			value += attn3 * attn3 * osg.extrapolate3(xsb+0, ysb+0, zsb+1, dx3, dy3, dz3)
+		}
+
+		// Contribution (1,1,0)
+		var dx4 float32 = dx0 - 1 - 2*squish
+		var dy4 float32 = dy0 - 1 - 2*squish
+		var dz4 float32 = dz0 - 0 - 2*squish
+		var attn4 float32 = 2 - dx4*dx4 - dy4*dy4 - dz4*dz4
+		if attn4 > 0 {
+			attn4 *= attn4
+			value += attn4 * attn4 * osg.extrapolate3(xsb+1, ysb+1, zsb+0, dx4, dy4, dz4)
+		}
+
+		// Contribution (1,0,1)
+		var dx5 float32 = dx4
+		var dy5 float32 = dy0 - 0 - 2*squish
+		var dz5 float32 = dz0 - 1 - 2*squish
+		var attn5 float32 = 2 - dx5*dx5 - dy5*dy5 - dz5*dz5
+		if attn5 > 0 {
+			attn5 *= attn5
+			value += attn5 * attn5 * osg.extrapolate3(xsb+1, ysb+0, zsb+1, dx5, dy5, dz5)
+		}
+
+		// Contribution (0,1,1)
+		var dx6 float32 = dx0 - 0 - 2*squish
+		var dy6 float32 = dy4
+		var dz6 float32 = dz5
+		var attn6 float32 = 2 - dx6*dx6 - dy6*dy6 - dz6*dz6
+		if attn6 > 0 {
+			attn6 *= attn6
+			value += attn6 * attn6 * osg.extrapolate3(xsb+0, ysb+1, zsb+1, dx6, dy6, dz6)
+		}
+	}
+
+	// First extra vertex
+	var attn_ext0 float32 = 2 - dx_ext0*dx_ext0 - dy_ext0*dy_ext0 - dz_ext0*dz_ext0
+	if attn_ext0 > 0 {
+		attn_ext0 *= attn_ext0
+		value += attn_ext0 * attn_ext0 * osg.extrapolate3(xsv_ext0, ysv_ext0, zsv_ext0, dx_ext0, dy_ext0, dz_ext0)
+	}
+
+	// Second extra vertex
+	var attn_ext1 float32 = 2 - dx_ext1*dx_ext1 - dy_ext1*dy_ext1 - dz_ext1*dz_ext1
+	if attn_ext1 > 0 {
+		attn_ext1 *= attn_ext1
+		value += attn_ext1 * attn_ext1 * osg.extrapolate3(xsv_ext1, ysv_ext1, zsv_ext1, dx_ext1, dy_ext1, dz_ext1)
+	}
+
+	return value / normConstant3D
+}
+
+/* ------------------------------------------------------------------------- */
+
+// FBMGenerator2D32 takes float32 noise and makes fractal Brownian motion values.
+type FBMGenerator2D32 struct {
+	NoiseMaker  NoiseyGet2D32 // the interface FBMGenerator2D32 uses gets noise values
+	Octaves     int           // the number of octaves to calculate on each Get()
+	Persistence float32       // a multiplier that determines how quickly the amplitudes diminish for each successive octave
+	Lacunarity  float32       // a multiplier that determines how quickly the frequency increases for each successive octave
+	Frequency   float32       // the number of cycles per unit length
+}
+
+// NewFBMGenerator2D32 creates a new float32 fractal Brownian motion generator state.
+// See NewFBMGenerator2D() for the parameters.
+func NewFBMGenerator2D32(noise NoiseyGet2D32, octaves int, persistence float32, lacunarity float32, frequency float32) (fbm FBMGenerator2D32) {
+	fbm.NoiseMaker = noise
+	fbm.Octaves = octaves
+	fbm.Persistence = persistence
+	fbm.Lacunarity = lacunarity
+	fbm.Frequency = frequency
+	return
+}
+
+// Get2D32 calculates the noise value over the number of Octaves and other parameters
+// that scale the coordinates over each octave.
+func (fbm *FBMGenerator2D32) Get2D32(x float32, y float32) (v float32) {
+	var curPersistence float32 = 1.0
+
+	x *= fbm.Frequency
+	y *= fbm.Frequency
+
+	for o := 0; o < fbm.Octaves; o++ {
+		signal := fbm.NoiseMaker.Get2D32(x, y)
+		v += signal * curPersistence
+
+		x *= fbm.Lacunarity
+		y *= fbm.Lacunarity
+		curPersistence *= fbm.Persistence
+	}
+
+	return
+}
+
+// FBMGenerator3D32 takes float32 noise and makes fractal Brownian motion values.
+type FBMGenerator3D32 struct {
+	NoiseMaker  NoiseyGet3D32 // the interface FBMGenerator3D32 uses gets noise values
+	Octaves     int           // the number of octaves to calculate on each Get()
+	Persistence float32       // a multiplier that determines how quickly the amplitudes diminish for each successive octave
+	Lacunarity  float32       // a multiplier that determines how quickly the frequency increases for each successive octave
+	Frequency   float32       // the number of cycles per unit length
+}
+
+// NewFBMGenerator3D32 creates a new float32 fractal Brownian motion generator state.
+// See NewFBMGenerator3D() for the parameters.
+func NewFBMGenerator3D32(noise NoiseyGet3D32, octaves int, persistence float32, lacunarity float32, frequency float32) (fbm FBMGenerator3D32) {
+	fbm.NoiseMaker = noise
+	fbm.Octaves = octaves
+	fbm.Persistence = persistence
+	fbm.Lacunarity = lacunarity
+	fbm.Frequency = frequency
+	return
+}
+
+// Get3D32 calculates the noise value over the number of Octaves and other parameters
+// that scale the coordinates over each octave.
+func (fbm *FBMGenerator3D32) Get3D32(x float32, y float32, z float32) (v float32) {
+	var curPersistence float32 = 1.0
+
+	x *= fbm.Frequency
+	y *= fbm.Frequency
+	z *= fbm.Frequency
+
+	for o := 0; o < fbm.Octaves; o++ {
+		signal := fbm.NoiseMaker.Get3D32(x, y, z)
+		v += signal * curPersistence
+
+		x *= fbm.Lacunarity
+		y *= fbm.Lacunarity
+		z *= fbm.Lacunarity
+		curPersistence *= fbm.Persistence
+	}
+
+	return v
+}
+
+/* ------------------------------------------------------------------------- */
+
+// Builder2DBounds32 is a simple rectangle type.
+type Builder2DBounds32 struct {
+	MinX, MinY, MaxX, MaxY float32
+}
+
+// Builder2D32 contains the parameters and data for the float32 noise 'map' generated with Build().
+type Builder2D32 struct {
+	Source NoiseyGet2D32
+	Width  int
+	Height int
+	Bounds Builder2DBounds32
+	Values []float32
+}
+
+// NewBuilder2D32 creates a new float32 2D noise 'map' builder of the given size
+func NewBuilder2D32(s NoiseyGet2D32, width int, height int) (b Builder2D32) {
+	b.Source = s
+	b.Width = width
+	b.Height = height
+	b.Values = make([]float32, width*height)
+	return
+}
+
+// Build gets noise from Source for each spot in the data array the same
+// way Builder2D.Build() does.
+func (b *Builder2D32) Build() {
+	// setup the initial parameters controlling how the noise is sampled
+	xExtent := b.Bounds.MaxX - b.Bounds.MinX
+	yExtent := b.Bounds.MaxY - b.Bounds.MinY
+	xDelta := xExtent / float32(b.Width)
+	yDelta := yExtent / float32(b.Height)
+	yCur := b.Bounds.MinY
+
+	for y := 0; y < b.Height; y++ {
+		xCur := b.Bounds.MinX
+		row := b.Values[y*b.Width : (y+1)*b.Width]
+		for x := range row {
+			row[x] = b.Source.Get2D32(xCur, yCur)
+			xCur += xDelta
+		}
+		yCur += yDelta
+	}
+}
+
+// GetMinMax returns the lowest and the highest Values
+func (b *Builder2D32) GetMinMax() (min float32, max float32) {
+	var low float32 = math.MaxFloat32
+	var high float32 = -math.MaxFloat32
+
+	for _, v := range b.Values[:b.Width*b.Height] {
+		if v < low {
+			low = v
+		}
+		if v > high {
+			high = v
+		}
+	}
+
+	return low, high
+}
@@ -0,0 +1,77 @@
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFloat32MatchesFloat64(t *testing.T) {
+	const tolerance = 1e-4
+
+	for _, quality := range []NoiseQuality{QualityAttenuated, QualityFast, QualityStandard, QualityBest} {
+		perlin := NewPerlinGeneratorSeed(3)
+		perlin.Quality = quality
+		perlin32 := NewPerlinGenerator32Seed(3)
+		perlin32.Quality = quality
+		for i := 0; i < 100; i++ {
+			x, y, z := float32(i)*0.37-12.0, float32(i)*0.91-40.0, float32(i)*0.13
+			v := perlin.Get2D(float64(x), float64(y))
+			v32 := perlin32.Get2D32(x, y)
+			if math.Abs(v-float64(v32)) > tolerance {
+				t.Errorf("perlin Get2D32(%v, %v) with quality %d = %v; expected %v", x, y, quality, v32, v)
+			}
+			v = perlin.Get3D(float64(x), float64(y), float64(z))
+			v32 = perlin32.Get3D32(x, y, z)
+			if math.Abs(v-float64(v32)) > tolerance {
+				t.Errorf("perlin Get3D32(%v, %v, %v) with quality %d = %v; expected %v", x, y, z, quality, v32, v)
+			}
+		}
+	}
+
+	simplex := NewOpenSimplexGeneratorSeed(3)
+	simplex32 := NewOpenSimplexGenerator32Seed(3)
+	fbm := NewFBMGenerator2D(&simplex, 4, 0.5, 2.0, 1.0)
+	fbm32 := NewFBMGenerator2D32(&simplex32, 4, 0.5, 2.0, 1.0)
+	for i := 0; i < 100; i++ {
+		x, y := float32(i)*0.37-12.0, float32(i)*0.91-40.0
+		v := fbm.Get2D(float64(x), float64(y))
+		v32 := fbm32.Get2D32(x, y)
+		if math.Abs(v-float64(v32)) > tolerance {
+			t.Errorf("opensimplex fBm Get2D32(%v, %v) = %v; expected %v", x, y, v32, v)
+		}
+	}
+}
+
+func TestOpenSimplex32MatchesFloat64(t *testing.T) {
+	// float32 keeps about 7 digits, and the error grows with the coordinates
+	const tolerance = 1e-4
+
+	for _, seed := range []int64{1, 3, 99} {
+		simplex := NewOpenSimplexGeneratorSeed(seed)
+		simplex32 := NewOpenSimplexGenerator32Seed(seed)
+		for i := -200; i < 200; i++ {
+			// include points on the lattice and in every part of the supercells
+			for _, offset := range []float32{0, 0.25, 0.5, 0.77} {
+				x, y := float32(i)*0.173+offset, float32(-i)*0.411+offset*2
+				v := simplex.Get2D(float64(x), float64(y))
+				v32 := simplex32.Get2D32(x, y)
+				if math.Abs(v-float64(v32)) > tolerance {
+					t.Errorf("opensimplex Get2D32(%v, %v) with seed %d = %v; expected %v", x, y, seed, v32, v)
+				}
+				if v32 < -1 || v32 > 1 {
+					t.Errorf("opensimplex Get2D32(%v, %v) with seed %d = %v is out of range", x, y, seed, v32)
+				}
+
+				z := float32(i)*0.29 - offset
+				v = simplex.Get3D(float64(x), float64(y), float64(z))
+				v32 = simplex32.Get3D32(x, y, z)
+				if math.Abs(v-float64(v32)) > tolerance {
+					t.Errorf("opensimplex Get3D32(%v, %v, %v) with seed %d = %v; expected %v", x, y, z, seed, v32, v)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+/*
+
+This module turns the values of a Builder2D into colors and images so that
+noise can be previewed or saved without any graphics library:
+
+  builder := noisey.NewBuilder2D(fbm, 256, 256)
+  builder.Bounds = noisey.Builder2DBounds{0.0, 0.0, 4.0, 4.0}
+  builder.Build()
+  img := builder.Image(noisey.TerrainGradient)
+  png.Encode(file, img)
+
+Noise values are expected to be in -1..1; values outside of that get the
+color at the nearest end of the gradient.
+
+*/
+
+import (
+	"image"
+	"image/color"
+	"sort"
+)
+
+// GradientPoint is a color at a position of a ColorGradient.
+type GradientPoint struct {
+	Value float64    // the noise value, usually in -1..1
+	Color color.RGBA // the color at Value
+}
+
+// ColorGradient maps noise values to colors by blending the colors of
+// the points around the value. The points must be sorted by Value.
+type ColorGradient []GradientPoint
+
+var (
+	// GrayscaleGradient maps -1..1 to black..white.
+	GrayscaleGradient = ColorGradient{
+		{-1.0, color.RGBA{0, 0, 0, 255}},
+		{1.0, color.RGBA{255, 255, 255, 255}},
+	}
+
+	// TerrainGradient maps -1..1 to deep water through grass and rock up to
+	// snow, using roughly the same bands as the colorized OpenGL example.
+	TerrainGradient = ColorGradient{
+		{-1.0, color.RGBA{0, 0, 128, 255}},     // deeps
+		{-0.75, color.RGBA{0, 0, 255, 255}},    // shallow
+		{-0.05, color.RGBA{0, 128, 255, 255}},  // shore
+		{-0.01, color.RGBA{240, 240, 64, 255}}, // sand
+		{0.03, color.RGBA{32, 160, 0, 255}},    // grass
+		{0.27, color.RGBA{224, 224, 0, 255}},   // dirt
+		{0.5, color.RGBA{128, 128, 128, 255}},  // rock
+		{0.96, color.RGBA{255, 255, 255, 255}}, // snow
+	}
+)
+
+// Color returns the color for the noise value v.
+func (g ColorGradient) Color(v float64) color.RGBA {
+	if len(g) == 0 {
+		return color.RGBA{}
+	}
+	i := sort.Search(len(g), func(i int) bool { return g[i].Value >= v })
+	if i == 0 {
+		return g[0].Color
+	}
+	if i == len(g) {
+		return g[len(g)-1].Color
+	}
+
+	a, b := g[i-1], g[i]
+	t := (v - a.Value) / (b.Value - a.Value)
+	blend := func(ca, cb uint8) uint8 {
+		return uint8(lerp(float64(ca), float64(cb), t) + 0.5)
+	}
+	return color.RGBA{blend(a.Color.R, b.Color.R), blend(a.Color.G, b.Color.G), blend(a.Color.B, b.Color.B), blend(a.Color.A, b.Color.A)}
+}
+
+// Image creates an RGBA image of the Values using the gradient to pick the colors.
+func (b *Builder2D) Image(gradient ColorGradient) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, b.Width, b.Height))
+	for y := 0; y < b.Height; y++ {
+		for x := 0; x < b.Width; x++ {
+			img.SetRGBA(x, y, gradient.Color(b.Values[y*b.Width+x]))
+		}
+	}
+	return img
+}
+
+// GrayImage creates a grayscale image of the Values, mapping -1..1 to black..white.
+func (b *Builder2D) GrayImage() *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, b.Width, b.Height))
+	for y := 0; y < b.Height; y++ {
+		for x := 0; x < b.Width; x++ {
+			img.SetGray(x, y, color.Gray{valueToByte(b.Values[y*b.Width+x])})
+		}
+	}
+	return img
+}
+
+// valueToByte maps a noise value in -1..1 to 0..255, clamping values outside of it.
+func valueToByte(v float64) uint8 {
+	v = (v*0.5 + 0.5) * 255.0
+	if v <= 0.0 {
+		return 0
+	}
+	if v >= 255.0 {
+		return 255
+	}
+	return uint8(v + 0.5)
+}
@@ -0,0 +1,56 @@
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestBuildParallel(t *testing.T) {
+	perlin := NewPerlinGeneratorSeed(1)
+	fbm := NewFBMGenerator2D(&perlin, 4, 0.5, 2.0, 1.0)
+
+	serial := NewBuilder2D(&fbm, 61, 37)
+	serial.Bounds = Builder2DBounds{-1.3, 0.7, 4.1, 3.3}
+	serial.Build()
+
+	for _, workers := range []int{1, 3, 8, 100} {
+		parallel := NewBuilder2D(&fbm, 61, 37)
+		parallel.Bounds = serial.Bounds
+		parallel.BuildParallel(workers)
+		for i := range serial.Values {
+			if parallel.Values[i] != serial.Values[i] {
+				t.Fatalf("BuildParallel(%d) differs from Build at %d: %f != %f", workers, i, parallel.Values[i], serial.Values[i])
+			}
+		}
+	}
+}
+
+func TestColorGradient(t *testing.T) {
+	g := ColorGradient{
+		{-1.0, color.RGBA{0, 0, 0, 255}},
+		{0.0, color.RGBA{200, 100, 0, 255}},
+		{1.0, color.RGBA{200, 100, 200, 255}},
+	}
+	if g.Color(-5.0) != g[0].Color || g.Color(5.0) != g[2].Color {
+		t.Errorf("ColorGradient doesn't clamp to its ends")
+	}
+	if c := g.Color(-0.5); c != (color.RGBA{100, 50, 0, 255}) {
+		t.Errorf("ColorGradient blended -0.5 to %v", c)
+	}
+	if c := g.Color(0.0); c != g[1].Color {
+		t.Errorf("ColorGradient returned %v at a point", c)
+	}
+
+	b := NewBuilder2D(&ConstGenerator{1.0}, 4, 3)
+	b.Build()
+	img := b.GrayImage()
+	if img.Bounds().Dx() != 4 || img.Bounds().Dy() != 3 || img.GrayAt(3, 2).Y != 255 {
+		t.Errorf("GrayImage didn't map 1.0 to white")
+	}
+	if b.Image(GrayscaleGradient).RGBAAt(1, 1) != (color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("Image didn't map 1.0 to white")
+	}
+}
@@ -0,0 +1,424 @@
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+/*
+
+This module implements the "Include" support for NoiseJSON configuration files
+so that a library of base seeds, sources and generators can be shared between
+many configuration files.
+
+A biome file that reuses the generators in a base file could look like this:
+
+{
+  "Include": [
+    "base.json"
+  ],
+  "IncludePolicy": "merge",
+  "Generators": [
+    {
+      "Name": "landcontrol",
+      "Octaves": 4
+    }
+  ]
+}
+
+Included paths are relative to the directory of the file including them.
+Included files are merged in order and then the definitions of the including
+file are applied on top of them according to IncludePolicy:
+
+  * "merge" (default) - fields present in the including file overwrite the
+    fields of the included definition with the same name; other fields are kept.
+  * "replace" - the including file's definition replaces the included one entirely.
+  * "error" - defining a name that already came from an included file is an error.
+
+When two included files define the same name, the later one wins unless the
+policy is "error". Every overridden definition is recorded in NoiseJSON.Conflicts
+along with the files involved.
+
+A file may be included by several files, like a base file shared by two biome
+files that are both included by a world file. Every including file gets its
+own copy of the shared file's definitions to apply its overrides to; getting
+the same definition from the same file twice isn't a conflict. Only a file
+that ends up including itself is an error.
+
+*/
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+)
+
+const (
+	// IncludePolicyMerge merges the fields of a definition with the included one.
+	IncludePolicyMerge = "merge"
+
+	// IncludePolicyReplace replaces an included definition entirely.
+	IncludePolicyReplace = "replace"
+
+	// IncludePolicyError treats redefining an included name as an error.
+	IncludePolicyError = "error"
+)
+
+// IncludeConflict describes a seed, source or generator that was defined in
+// more than one configuration file.
+type IncludeConflict struct {
+	Kind           string // "Param", "Seed", "Source" or "Generator"
+	Name           string // the name of the definition
+	File           string // the file whose definition is used
+	OverriddenFile string // the file whose definition was overridden
+}
+
+// String returns a readable description of the conflict.
+func (c IncludeConflict) String() string {
+	return fmt.Sprintf("%s \"%s\" from %s overrides the definition from %s", c.Kind, c.Name, originName(c.File), originName(c.OverriddenFile))
+}
+
+// noiseJSONRaw mirrors NoiseJSON but keeps the definitions as raw JSON
+// so that they can be merged field by field onto included definitions.
+type noiseJSONRaw struct {
+	Include       []string
+	IncludePolicy string
+	Params        map[string]float64
+	Seeds         map[string]int64
+	Sources       map[string]json.RawMessage
+	Generators    []json.RawMessage
+}
+
+// noiseJSONLoader keeps track of the files visited while resolving includes.
+type noiseJSONLoader struct {
+	loading map[string]bool // files currently being loaded; used to detect cycles
+	files   []string        // every file that was read, in load order; may repeat
+}
+
+// LoadNoiseJSONFile reads the configuration file, resolves its "Include"
+// list recursively and returns the merged NoiseJSON object on success;
+// error otherwise.
+func LoadNoiseJSONFile(filename string) (*NoiseJSON, error) {
+	loader := newNoiseJSONLoader()
+	cfg, err := loader.loadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	loader.finish(cfg)
+	return cfg, nil
+}
+
+// Files returns the paths of every configuration file that contributed to
+// this NoiseJSON when it was loaded with LoadNoiseJSONFile(), starting with
+// the deepest include.
+func (cfg *NoiseJSON) Files() []string {
+	return cfg.files
+}
+
+func newNoiseJSONLoader() *noiseJSONLoader {
+	l := new(noiseJSONLoader)
+	l.loading = make(map[string]bool)
+	return l
+}
+
+// finish records the files that were read in the fully merged cfg, dropping
+// the repeats that files included more than once leave behind.
+func (l *noiseJSONLoader) finish(cfg *NoiseJSON) {
+	cfg.files = uniqueStrings(l.files)
+	cfg.Conflicts = uniqueConflicts(cfg.Conflicts)
+}
+
+// loadFile reads a configuration file and merges in its includes.
+func (l *noiseJSONLoader) loadFile(filename string) (*NoiseJSON, error) {
+	absName, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to resolve the path of %s.\n%v\n", filename, err)
+	}
+	if l.loading[absName] {
+		return nil, fmt.Errorf("Configuration file %s includes itself.\n", absName)
+	}
+
+	bytes, err := ioutil.ReadFile(absName)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read the noise configuration file %s.\n%v\n", absName, err)
+	}
+
+	l.loading[absName] = true
+	defer delete(l.loading, absName)
+	cfg, err := l.load(bytes, filepath.Dir(absName), absName)
+	if err != nil {
+		return nil, err
+	}
+	l.files = append(l.files, absName)
+	return cfg, nil
+}
+
+// load parses the JSON bytes of the file origin, loads the included files
+// relative to dir and merges everything together into a new NoiseJSON.
+func (l *noiseJSONLoader) load(bytes []byte, dir string, origin string) (*NoiseJSON, error) {
+	var raw noiseJSONRaw
+	err := json.Unmarshal(bytes, &raw)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read json from %s into the configuration structure.\n%v\n", originName(origin), err)
+	}
+
+	policy := raw.IncludePolicy
+	if policy == "" {
+		policy = IncludePolicyMerge
+	}
+	if policy != IncludePolicyMerge && policy != IncludePolicyReplace && policy != IncludePolicyError {
+		return nil, fmt.Errorf("Undefined include policy (%s) in %s.\n", raw.IncludePolicy, originName(origin))
+	}
+
+	cfg := NewNoiseJSON()
+	cfg.origins = make(map[string]string)
+
+	// merge the included files in order; later includes override earlier ones
+	for _, inc := range raw.Include {
+		path := inc
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		included, err := l.loadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", originName(origin), err)
+		}
+		err = cfg.mergeIncluded(included, policy)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// apply the definitions from this file on top of the included ones
+	for name, value := range raw.Params {
+		err = cfg.claim("Param", name, origin, policy)
+		if err != nil {
+			return nil, err
+		}
+		cfg.SetParam(name, value)
+	}
+
+	for name, seed := range raw.Seeds {
+		err = cfg.claim("Seed", name, origin, policy)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Seeds[name] = seed
+	}
+
+	for name, rawSource := range raw.Sources {
+		err = cfg.claim("Source", name, origin, policy)
+		if err != nil {
+			return nil, err
+		}
+		var source SourceJSON
+		if policy == IncludePolicyMerge {
+			source = cfg.Sources[name]
+			source.Expressions = copyExpressions(source.Expressions)
+		}
+		err = json.Unmarshal(rawSource, &source)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to read Source \"%s\" from %s.\n%v\n", name, originName(origin), err)
+		}
+		cfg.Sources[name] = source
+	}
+
+	for _, rawGen := range raw.Generators {
+		var named struct{ Name string }
+		err = json.Unmarshal(rawGen, &named)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to read a Generator from %s.\n%v\n", originName(origin), err)
+		}
+		err = cfg.claim("Generator", named.Name, origin, policy)
+		if err != nil {
+			return nil, err
+		}
+
+		index := cfg.generatorIndex(named.Name)
+		var gen GeneratorJSON
+		if index >= 0 && policy == IncludePolicyMerge {
+			gen = cfg.Generators[index]
+			gen.Expressions = copyExpressions(gen.Expressions)
+		}
+		err = json.Unmarshal(rawGen, &gen)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to read Generator \"%s\" from %s.\n%v\n", named.Name, originName(origin), err)
+		}
+
+		// overridden generators keep their place in the order
+		if index >= 0 {
+			cfg.Generators[index] = gen
+		} else {
+			cfg.Generators = append(cfg.Generators, gen)
+		}
+	}
+
+	if len(raw.Include) > 0 {
+		err = cfg.sortGenerators()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", originName(origin), err)
+		}
+	}
+
+	return cfg, nil
+}
+
+// mergeIncluded copies all of the definitions from an included NoiseJSON
+// into cfg, replacing definitions with the same name.
+func (cfg *NoiseJSON) mergeIncluded(inc *NoiseJSON, policy string) error {
+	cfg.Conflicts = append(cfg.Conflicts, inc.Conflicts...)
+
+	for name, value := range inc.Params {
+		err := cfg.claim("Param", name, inc.origins["Param/"+name], policy)
+		if err != nil {
+			return err
+		}
+		cfg.SetParam(name, value)
+	}
+	for name, seed := range inc.Seeds {
+		err := cfg.claim("Seed", name, inc.origins["Seed/"+name], policy)
+		if err != nil {
+			return err
+		}
+		cfg.Seeds[name] = seed
+	}
+	for name, source := range inc.Sources {
+		err := cfg.claim("Source", name, inc.origins["Source/"+name], policy)
+		if err != nil {
+			return err
+		}
+		cfg.Sources[name] = source
+	}
+	for _, gen := range inc.Generators {
+		err := cfg.claim("Generator", gen.Name, inc.origins["Generator/"+gen.Name], policy)
+		if err != nil {
+			return err
+		}
+		index := cfg.generatorIndex(gen.Name)
+		if index >= 0 {
+			cfg.Generators[index] = gen
+		} else {
+			cfg.Generators = append(cfg.Generators, gen)
+		}
+	}
+
+	return nil
+}
+
+// claim records origin as the file defining the named object, recording a
+// conflict if it was already defined or returning an error if the policy
+// doesn't allow that.
+func (cfg *NoiseJSON) claim(kind string, name string, origin string, policy string) error {
+	key := kind + "/" + name
+	previous, exists := cfg.origins[key]
+	if exists && previous != origin {
+		if policy == IncludePolicyError {
+			return fmt.Errorf("%s \"%s\" is defined in both %s and %s.\n", kind, name, originName(previous), originName(origin))
+		}
+		cfg.Conflicts = append(cfg.Conflicts, IncludeConflict{kind, name, origin, previous})
+	}
+	cfg.origins[key] = origin
+	return nil
+}
+
+// generatorIndex returns the index of the named generator in Generators or -1.
+func (cfg *NoiseJSON) generatorIndex(name string) int {
+	for i, gen := range cfg.Generators {
+		if gen.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// sortGenerators reorders Generators so that every generator comes after the
+// generators it references, keeping the existing order wherever possible.
+// This is needed because overrides and includes can reference generators
+// that were defined later in the merged list.
+func (cfg *NoiseJSON) sortGenerators() error {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int)
+	sorted := make([]GeneratorJSON, 0, len(cfg.Generators))
+
+	var visit func(i int) error
+	visit = func(i int) error {
+		gen := cfg.Generators[i]
+		switch state[gen.Name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("Generator \"%s\" depends on itself.\n", gen.Name)
+		}
+		state[gen.Name] = visiting
+		for _, dep := range gen.Generators {
+			// unknown names are reported later by BuildGenerators()
+			if j := cfg.generatorIndex(dep); j >= 0 {
+				if err := visit(j); err != nil {
+					return err
+				}
+			}
+		}
+		state[gen.Name] = visited
+		sorted = append(sorted, gen)
+		return nil
+	}
+
+	for i := range cfg.Generators {
+		if err := visit(i); err != nil {
+			return err
+		}
+	}
+	cfg.Generators = sorted
+	return nil
+}
+
+// copyExpressions returns a copy of the expressions map so that merging
+// onto a definition doesn't modify the included one.
+func copyExpressions(exprs map[string]string) map[string]string {
+	if exprs == nil {
+		return nil
+	}
+	c := make(map[string]string, len(exprs))
+	for k, v := range exprs {
+		c[k] = v
+	}
+	return c
+}
+
+// uniqueStrings returns the strings without repeats, keeping the first of each.
+func uniqueStrings(strs []string) []string {
+	seen := make(map[string]bool)
+	unique := make([]string, 0, len(strs))
+	for _, s := range strs {
+		if !seen[s] {
+			seen[s] = true
+			unique = append(unique, s)
+		}
+	}
+	return unique
+}
+
+// uniqueConflicts returns the conflicts without repeats, which happen when a
+// file with conflicts of its own is included more than once.
+func uniqueConflicts(conflicts []IncludeConflict) []IncludeConflict {
+	seen := make(map[IncludeConflict]bool)
+	var unique []IncludeConflict
+	for _, c := range conflicts {
+		if !seen[c] {
+			seen[c] = true
+			unique = append(unique, c)
+		}
+	}
+	return unique
+}
+
+// originName returns a printable name for the origin of a definition.
+func originName(origin string) string {
+	if origin == "" {
+		return "<bytes>"
+	}
+	return origin
+}
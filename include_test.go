@@ -0,0 +1,120 @@
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeConfigFiles writes the named configuration files to a new temporary
+// directory and returns its path.
+func writeConfigFiles(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "noisey")
+	if err != nil {
+		t.Fatalf("Unable to create a temporary directory: %v", err)
+	}
+	for name, contents := range files {
+		err = ioutil.WriteFile(filepath.Join(dir, name), []byte(contents), 0644)
+		if err != nil {
+			t.Fatalf("Unable to write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+const includeBaseJSON = `{
+	"Seeds": { "Default": 1 },
+	"Sources": {
+		"perlin": { "SourceType": "perlin", "Seed": "Default" }
+	},
+	"Generators": [
+		{ "Name": "land", "GeneratorType": "fBm2d", "Sources": ["perlin"], "Octaves": 5, "Persistence": 0.5, "Lacunarity": 2.0, "Frequency": 1.0 }
+	]
+}`
+
+func TestIncludeOverride(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"base.json": includeBaseJSON,
+		"biome.json": `{
+			"Include": ["base.json"],
+			"Generators": [ { "Name": "land", "Octaves": 3 } ]
+		}`,
+	})
+	defer os.RemoveAll(dir)
+
+	cfg, err := LoadNoiseJSONFile(filepath.Join(dir, "biome.json"))
+	if err != nil {
+		t.Fatalf("Unable to load the biome: %v", err)
+	}
+	if len(cfg.Generators) != 1 {
+		t.Fatalf("The biome has %d generators", len(cfg.Generators))
+	}
+	land := cfg.Generators[0]
+	if land.Octaves != 3 || land.GeneratorType != "fBm2d" || land.Persistence != 0.5 || len(land.Sources) != 1 {
+		t.Errorf("The override wasn't merged onto the included generator: %+v", land)
+	}
+	if len(cfg.Conflicts) != 1 || cfg.Conflicts[0].Name != "land" {
+		t.Errorf("The override should be the only conflict: %v", cfg.Conflicts)
+	}
+	if len(cfg.Files()) != 2 {
+		t.Errorf("The biome was loaded from %v", cfg.Files())
+	}
+}
+
+func TestIncludeDiamond(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"base.json": includeBaseJSON,
+		"b.json":    `{ "Include": ["base.json"], "Generators": [ { "Name": "hills", "GeneratorType": "fBm2d", "Sources": ["perlin"], "Octaves": 2 } ] }`,
+		"c.json":    `{ "Include": ["base.json"], "Generators": [ { "Name": "land", "Octaves": 3 } ] }`,
+		"a.json":    `{ "Include": ["b.json", "c.json"] }`,
+	})
+	defer os.RemoveAll(dir)
+
+	cfg, err := LoadNoiseJSONFile(filepath.Join(dir, "a.json"))
+	if err != nil {
+		t.Fatalf("Unable to load the diamond: %v", err)
+	}
+	err = cfg.BuildSources(nil)
+	if err == nil {
+		err = cfg.BuildGenerators()
+	}
+	if err != nil {
+		t.Fatalf("Unable to build the diamond: %v", err)
+	}
+
+	land := cfg.Generators[cfg.generatorIndex("land")]
+	if land.Octaves != 3 || land.GeneratorType != "fBm2d" || len(land.Sources) != 1 {
+		t.Errorf("The land generator from c.json lost the fields from base.json: %+v", land)
+	}
+	if cfg.GetGenerator("hills") == nil || cfg.GetGenerator("land") == nil {
+		t.Errorf("The generators from both sides of the diamond should be built")
+	}
+
+	// base.json is read twice but only listed once, and is no conflict with itself
+	if len(cfg.Files()) != 4 {
+		t.Errorf("The diamond was loaded from %v", cfg.Files())
+	}
+	for _, c := range cfg.Conflicts {
+		if c.File == c.OverriddenFile {
+			t.Errorf("A file conflicts with itself: %v", c)
+		}
+	}
+}
+
+func TestIncludeCycle(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"a.json": `{ "Include": ["b.json"] }`,
+		"b.json": `{ "Include": ["a.json"] }`,
+	})
+	defer os.RemoveAll(dir)
+
+	_, err := LoadNoiseJSONFile(filepath.Join(dir, "a.json"))
+	if err == nil || !strings.Contains(err.Error(), "includes itself") {
+		t.Errorf("The include cycle wasn't reported: %v", err)
+	}
+}
@@ -0,0 +1,236 @@
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+/*
+
+This module extracts isosurfaces from 3D noise as meshes, for things like
+caves and asteroids. Everything with a value above the iso level counts as
+solid and the mesh wraps it:
+
+  fbm := noisey.NewFBMGenerator3D(&perlin, 4, 0.5, 2.0, 1.0)
+  mesh := noisey.SurfaceNets3D(&fbm, noisey.Vec3f{0, 0, 0}, noisey.Vec3f{8, 8, 8}, noisey.Vec3i{64, 64, 64}, 0.0)
+  mesh.WriteOBJ(objFile)
+
+The source is sampled on a grid of size.X x size.Y x size.Z points that
+includes both min and max, so neighbouring volumes that share a face line up.
+Values that were already sampled can be passed to SurfaceNets() directly.
+
+The surface is built with the naive surface nets algorithm: every grid cell
+the surface passes through gets one vertex at the average of the points where
+the surface crosses the cell's edges, and every grid edge crossing the
+surface gets a quad joining the vertices of the four cells around it. The
+meshes are smoother and have fewer triangles than the ones made by marching
+cubes and don't need its lookup tables. The triangles are wound counter-
+clockwise seen from outside the solid and the normals point out of it.
+
+Surfaces are left open where they meet the bounds; keep the values at the
+bounds below the iso level, e.g. with a Select3D on a SpheresGenerator, to get
+closed meshes.
+
+*/
+
+import (
+	"math"
+)
+
+// SurfaceNets3D samples src on a grid of size points spanning min..max and
+// returns the mesh of the surface where its values cross isoLevel.
+func SurfaceNets3D(src NoiseyGet3D, min, max Vec3f, size Vec3i, isoLevel float64) *Mesh {
+	if size.X < 2 || size.Y < 2 || size.Z < 2 {
+		return new(Mesh)
+	}
+	step := gridStep(min, max, size)
+	values := make([]float64, size.X*size.Y*size.Z)
+	GetGrid3D(src, min.X, min.Y, min.Z, step.X, step.Y, step.Z, size.X, size.Y, size.Z, values)
+	return SurfaceNets(values, size, min, max, isoLevel)
+}
+
+// SurfaceNets returns the mesh of the surface where values cross isoLevel.
+// values holds a grid of size points spanning min..max, ordered like the
+// output of GetGrid3D(): X first, then Y, then Z.
+func SurfaceNets(values []float64, size Vec3i, min, max Vec3f, isoLevel float64) *Mesh {
+	mesh := new(Mesh)
+	if size.X < 2 || size.Y < 2 || size.Z < 2 {
+		return mesh
+	}
+
+	sn := &surfaceNets{values: values, size: size, min: min, step: gridStep(min, max, size), iso: isoLevel}
+	cells := Vec3i{size.X - 1, size.Y - 1, size.Z - 1}
+	cellVertex := make([]int, cells.X*cells.Y*cells.Z)
+
+	// place a vertex in every cell the surface passes through
+	for z := 0; z < cells.Z; z++ {
+		for y := 0; y < cells.Y; y++ {
+			for x := 0; x < cells.X; x++ {
+				cell := (z*cells.Y+y)*cells.X + x
+				cellVertex[cell] = -1
+				pos, ok := sn.cellVertex(x, y, z)
+				if !ok {
+					continue
+				}
+				cellVertex[cell] = len(mesh.Vertices)
+				mesh.Vertices = append(mesh.Vertices, sn.position(pos))
+				mesh.Normals = append(mesh.Normals, sn.normal(pos))
+			}
+		}
+	}
+
+	cellIndex := func(x, y, z int) uint32 {
+		return uint32(cellVertex[(z*cells.Y+y)*cells.X+x])
+	}
+
+	// join the cells around every edge that crosses the surface
+	for z := 0; z < size.Z; z++ {
+		for y := 0; y < size.Y; y++ {
+			for x := 0; x < size.X; x++ {
+				inside := sn.value(x, y, z) > isoLevel
+
+				// edges along X are surrounded by the cells in the YZ plane
+				if x+1 < size.X && y > 0 && z > 0 && y < size.Y-1 && z < size.Z-1 {
+					if other := sn.value(x+1, y, z) > isoLevel; other != inside {
+						mesh.addQuad(inside, cellIndex(x, y-1, z-1), cellIndex(x, y, z-1), cellIndex(x, y, z), cellIndex(x, y-1, z))
+					}
+				}
+				// edges along Y are surrounded by the cells in the ZX plane
+				if y+1 < size.Y && z > 0 && x > 0 && z < size.Z-1 && x < size.X-1 {
+					if other := sn.value(x, y+1, z) > isoLevel; other != inside {
+						mesh.addQuad(inside, cellIndex(x-1, y, z-1), cellIndex(x-1, y, z), cellIndex(x, y, z), cellIndex(x, y, z-1))
+					}
+				}
+				// edges along Z are surrounded by the cells in the XY plane
+				if z+1 < size.Z && x > 0 && y > 0 && x < size.X-1 && y < size.Y-1 {
+					if other := sn.value(x, y, z+1) > isoLevel; other != inside {
+						mesh.addQuad(inside, cellIndex(x-1, y-1, z), cellIndex(x, y-1, z), cellIndex(x, y, z), cellIndex(x-1, y, z))
+					}
+				}
+			}
+		}
+	}
+
+	return mesh
+}
+
+// addQuad adds the quad abcd as two triangles. The corners are counter-
+// clockwise seen from the positive end of the edge the quad crosses, which
+// is outside the solid if the edge starts inside; otherwise they get flipped.
+func (m *Mesh) addQuad(forward bool, a, b, c, d uint32) {
+	if forward {
+		m.Indices = append(m.Indices, a, b, c, a, c, d)
+	} else {
+		m.Indices = append(m.Indices, a, c, b, a, d, c)
+	}
+}
+
+// gridStep returns the distance between the points of a grid of size points
+// spanning min..max.
+func gridStep(min, max Vec3f, size Vec3i) Vec3f {
+	return Vec3f{
+		(max.X - min.X) / float64(size.X-1),
+		(max.Y - min.Y) / float64(size.Y-1),
+		(max.Z - min.Z) / float64(size.Z-1),
+	}
+}
+
+// surfaceNets holds the grid an isosurface is extracted from.
+type surfaceNets struct {
+	values []float64
+	size   Vec3i
+	min    Vec3f
+	step   Vec3f
+	iso    float64
+}
+
+// value returns the value at the grid point (x, y, z).
+func (sn *surfaceNets) value(x, y, z int) float64 {
+	return sn.values[(z*sn.size.Y+y)*sn.size.X+x]
+}
+
+// cellVertex returns the vertex of the cell starting at (x, y, z) in grid
+// coordinates, and whether the surface passes through the cell at all.
+func (sn *surfaceNets) cellVertex(x, y, z int) (Vec3f, bool) {
+	var corners [8]float64
+	var mask int
+	for i := 0; i < 8; i++ {
+		corners[i] = sn.value(x+i&1, y+(i>>1)&1, z+(i>>2)&1)
+		if corners[i] > sn.iso {
+			mask |= 1 << uint(i)
+		}
+	}
+	if mask == 0 || mask == 0xff {
+		return Vec3f{}, false
+	}
+
+	// average the crossings of the 12 edges: each joins corners one bit apart
+	var sum Vec3f
+	crossings := 0
+	for a := 0; a < 8; a++ {
+		for bit := uint(0); bit < 3; bit++ {
+			b := a | 1<<bit
+			if b == a || (mask>>uint(a))&1 == (mask>>uint(b))&1 {
+				continue
+			}
+			t := (sn.iso - corners[a]) / (corners[b] - corners[a])
+			p := Vec3f{float64(x + a&1), float64(y + (a>>1)&1), float64(z + (a>>2)&1)}
+			switch bit {
+			case 0:
+				p.X += t
+			case 1:
+				p.Y += t
+			case 2:
+				p.Z += t
+			}
+			sum.X, sum.Y, sum.Z = sum.X+p.X, sum.Y+p.Y, sum.Z+p.Z
+			crossings++
+		}
+	}
+	n := float64(crossings)
+	return Vec3f{sum.X / n, sum.Y / n, sum.Z / n}, true
+}
+
+// position converts grid coordinates to the coordinates of the volume.
+func (sn *surfaceNets) position(p Vec3f) Vec3f {
+	return Vec3f{sn.min.X + p.X*sn.step.X, sn.min.Y + p.Y*sn.step.Y, sn.min.Z + p.Z*sn.step.Z}
+}
+
+// normal returns the outward normal at the grid coordinates p, which is the
+// direction the values fall fastest in, interpolated from the gradients at
+// the corners of the cell around p.
+func (sn *surfaceNets) normal(p Vec3f) Vec3f {
+	x0 := minInt(int(math.Floor(p.X)), sn.size.X-2)
+	y0 := minInt(int(math.Floor(p.Y)), sn.size.Y-2)
+	z0 := minInt(int(math.Floor(p.Z)), sn.size.Z-2)
+	fx, fy, fz := p.X-float64(x0), p.Y-float64(y0), p.Z-float64(z0)
+
+	var g Vec3f
+	for i := 0; i < 8; i++ {
+		cx, cy, cz := x0+i&1, y0+(i>>1)&1, z0+(i>>2)&1
+		w := trilinearWeight(fx, i&1) * trilinearWeight(fy, (i>>1)&1) * trilinearWeight(fz, (i>>2)&1)
+		c := sn.gradient(cx, cy, cz)
+		g.X, g.Y, g.Z = g.X+c.X*w, g.Y+c.Y*w, g.Z+c.Z*w
+	}
+	return normalize3f(Vec3f{-g.X, -g.Y, -g.Z})
+}
+
+// gradient returns the gradient of the values at the grid point (x, y, z),
+// using one sided differences at the bounds.
+func (sn *surfaceNets) gradient(x, y, z int) Vec3f {
+	x0, x1 := maxInt(x-1, 0), minInt(x+1, sn.size.X-1)
+	y0, y1 := maxInt(y-1, 0), minInt(y+1, sn.size.Y-1)
+	z0, z1 := maxInt(z-1, 0), minInt(z+1, sn.size.Z-1)
+	return Vec3f{
+		(sn.value(x1, y, z) - sn.value(x0, y, z)) / (float64(x1-x0) * sn.step.X),
+		(sn.value(x, y1, z) - sn.value(x, y0, z)) / (float64(y1-y0) * sn.step.Y),
+		(sn.value(x, y, z1) - sn.value(x, y, z0)) / (float64(z1-z0) * sn.step.Z),
+	}
+}
+
+// trilinearWeight returns the trilinear weight of a corner: f for the far corner
+// (bit 1) and 1 - f for the near one.
+func trilinearWeight(f float64, bit int) float64 {
+	if bit == 1 {
+		return f
+	}
+	return 1.0 - f
+}
@@ -0,0 +1,72 @@
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+import (
+	"math"
+	"testing"
+)
+
+// ballSource is 1 at the origin and falls off with the distance from it.
+type ballSource struct{}
+
+func (b *ballSource) Get3D(x, y, z float64) float64 {
+	return 1.0 - math.Sqrt(x*x+y*y+z*z)
+}
+
+func TestSurfaceNets3D(t *testing.T) {
+	// a sphere of radius 0.5 sampled every 0.1 units
+	mesh := SurfaceNets3D(&ballSource{}, Vec3f{-1, -1, -1}, Vec3f{1, 1, 1}, Vec3i{21, 21, 21}, 0.5)
+	if mesh.TriangleCount() == 0 || len(mesh.Normals) != len(mesh.Vertices) {
+		t.Fatalf("SurfaceNets3D built %d triangles and %d normals for %d vertices", mesh.TriangleCount(), len(mesh.Normals), len(mesh.Vertices))
+	}
+
+	for i, v := range mesh.Vertices {
+		r := math.Sqrt(v.X*v.X + v.Y*v.Y + v.Z*v.Z)
+		if math.Abs(r-0.5) > 0.05 {
+			t.Errorf("Vertex %v is %f from the center", v, r)
+		}
+		n := mesh.Normals[i]
+		if n.X*v.X+n.Y*v.Y+n.Z*v.Z <= 0.0 {
+			t.Errorf("The normal %v of vertex %v doesn't point out", n, v)
+		}
+	}
+
+	// every triangle faces out and every edge is shared by two triangles
+	edges := make(map[[2]uint32]int)
+	for i := 0; i < len(mesh.Indices); i += 3 {
+		tri := mesh.Indices[i : i+3]
+		a, b, c := mesh.Vertices[tri[0]], mesh.Vertices[tri[1]], mesh.Vertices[tri[2]]
+		n := faceNormal(a, b, c)
+		if n.X*(a.X+b.X+c.X)+n.Y*(a.Y+b.Y+c.Y)+n.Z*(a.Z+b.Z+c.Z) <= 0.0 {
+			t.Fatalf("Triangle %d faces inward", i/3)
+		}
+		for k := 0; k < 3; k++ {
+			e := [2]uint32{tri[k], tri[(k+1)%3]}
+			if e[0] > e[1] {
+				e[0], e[1] = e[1], e[0]
+			}
+			edges[e]++
+		}
+	}
+	for e, count := range edges {
+		if count != 2 {
+			t.Fatalf("Edge %v is used by %d triangles; the sphere isn't closed", e, count)
+		}
+	}
+}
+
+func TestSurfaceNets3DGenerators(t *testing.T) {
+	perlin := NewPerlinGeneratorSeed(1)
+	fbm := NewFBMGenerator3D(&perlin, 3, 0.5, 2.0, 1.0)
+	ball := SpheresGenerator{0.25}
+	sel := NewSelect3D(&fbm, &ConstGenerator{-1.0}, &ball, 0.0, 1.0, 0.0)
+
+	for name, src := range map[string]NoiseyGet3D{"fBm": &fbm, "select": &sel} {
+		mesh := SurfaceNets3D(src, Vec3f{-2, -2, -2}, Vec3f{2, 2, 2}, Vec3i{17, 17, 17}, 0.0)
+		if mesh.TriangleCount() == 0 {
+			t.Errorf("SurfaceNets3D didn't find a surface in the %s generator", name)
+		}
+	}
+}
@@ -17,7 +17,7 @@ A sample JSON file would contain something like this:
   },
   "Sources": {
     "perlin": {
-      "SourceType": "perlin2d",
+      "SourceType": "perlin",
       "Quality": 2,
       "Seed": "Default"
     }
@@ -36,6 +36,8 @@ A sample JSON file would contain something like this:
   }
 }
 
+The "Quality": 2 above selects the quintic interpolation, which is the best
+quality in libnoise's numbering; see SourceJSON.Quality.
 
 A quick sample of what this looks like is here:
 
@@ -55,7 +57,7 @@ A quick sample of what this looks like is here:
   }
 
   err = noiseBank.BuildSources(func(s int64) noisey.RandomSource {
-    return rand.New(rand.NewSource(int64(s)))
+    return noisey.NewPCG32(s)
     })
   if err != nil {
     panic(err)
@@ -86,7 +88,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"math/rand"
+	"strings"
 )
 
 // RandomSeedBuilder is a type used to construct RandomSource interfaces
@@ -124,6 +126,38 @@ type GeneratorJSON struct {
 	Bias        float64 // Scale is generator specific ...
 	Min         float64 // Min is generator specific ...
 	Max         float64 // Min is generator specific ...
+	Exponent    float64 // Exponent is generator specific ...
+
+	// Samples is the number of random points that "normalize2d" and
+	// "normalize3d" generators sample to estimate the range of their input;
+	// 0 (or less) uses the bound of an fBm input instead.
+	Samples int `json:",omitempty"`
+
+	// SampleBounds is the region the normalize generators sample: MinX, MinY,
+	// MaxX, MaxY for "normalize2d" and MinX, MinY, MinZ, MaxX, MaxY, MaxZ for
+	// "normalize3d". It defaults to -64..64 on every axis.
+	SampleBounds []float64 `json:",omitempty"`
+
+	// Expressions maps the names of numeric fields to the expressions that
+	// were given for them as strings in the JSON; they get evaluated against
+	// NoiseJSON.Params on BuildGenerators(). See expr.go for the details.
+	Expressions map[string]string `json:"-"`
+}
+
+// generatorJSONFields is used to (un)marshal GeneratorJSON without recursing
+// into its custom methods.
+type generatorJSONFields GeneratorJSON
+
+// UnmarshalJSON reads the generator, keeping numeric fields given as
+// expression strings in Expressions.
+func (gen *GeneratorJSON) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExpressions(data, (*generatorJSONFields)(gen), &gen.Expressions)
+}
+
+// MarshalJSON writes the generator, writing Expressions in place of the
+// numeric fields they are bound to.
+func (gen GeneratorJSON) MarshalJSON() ([]byte, error) {
+	return marshalWithExpressions(generatorJSONFields(gen), gen.Expressions)
 }
 
 // SourceJSON describes the source of the random information, like perlin2d.
@@ -135,11 +169,85 @@ type SourceJSON struct {
 	// Seed is a string that needs to be a name in the NoiseJSON.Seeds map that
 	// is to be used in this generator.
 	Seed string
+
+	// Quality selects the interpolation used by "perlin" and "value" sources
+	// with libnoise's numbering: 0 is fast (linear), 1 is standard (cubic) and
+	// 2 is best (quintic). -1 selects the original attenuated blend of "perlin",
+	// which "value" doesn't have. If it's left out, "perlin" uses the attenuated
+	// blend and "value" uses cubic. See the NoiseQuality constants.
+	Quality *int `json:",omitempty"`
+
+	// FullHash makes "perlin" and "opensimplex" sources hash the full 64 bit
+	// lattice coordinates so that the noise doesn't repeat every 256 units.
+	FullHash bool `json:",omitempty"`
+
+	// Period makes "perlin" sources tileable. It holds up to three integer
+	// periods for the X, Y and Z axes; a period of 0 doesn't wrap. Setting a
+	// positive period on an "opensimplex" source is an error.
+	Period []int `json:",omitempty"`
+
+	// Frequency is used by the "checkerboard", "cylinders" and "spheres"
+	// sources; 0 means the default frequency of 1.0.
+	Frequency float64 `json:",omitempty"`
+
+	// Value is the value returned by "const" sources.
+	Value float64 `json:",omitempty"`
+
+	// Expressions maps the names of numeric fields to the expressions that
+	// were given for them as strings in the JSON; they get evaluated against
+	// NoiseJSON.Params on BuildSources(). See expr.go for the details.
+	Expressions map[string]string `json:"-"`
+}
+
+// sourceJSONFields is used to (un)marshal SourceJSON without recursing
+// into its custom methods.
+type sourceJSONFields SourceJSON
+
+// UnmarshalJSON reads the source, keeping numeric fields given as
+// expression strings in Expressions.
+func (source *SourceJSON) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExpressions(data, (*sourceJSONFields)(source), &source.Expressions)
+}
+
+// MarshalJSON writes the source, writing Expressions in place of the
+// numeric fields they are bound to.
+func (source SourceJSON) MarshalJSON() ([]byte, error) {
+	return marshalWithExpressions(sourceJSONFields(source), source.Expressions)
+}
+
+// noiseQuality returns the NoiseQuality for the libnoise style Quality number
+// of the source, or def if it's left out. The attenuated blend (-1) is only
+// accepted if attenuated is true.
+func (source *SourceJSON) noiseQuality(def NoiseQuality, attenuated bool) (NoiseQuality, error) {
+	if source.Quality == nil {
+		return def, nil
+	}
+	q := *source.Quality
+	switch {
+	case q == -1 && attenuated:
+		return QualityAttenuated, nil
+	case q >= 0 && q <= 2:
+		return NoiseQuality(q + 1), nil
+	}
+	return def, fmt.Errorf("Undefined quality (%d) for source type %s.", q, source.SourceType)
 }
 
 // NoiseJSON is a structure that facilities the saving and loading of JSON
 // representations of a system of seeds, sources and generators of noise.
 type NoiseJSON struct {
+	// Include is a list of other configuration files whose seeds, sources and
+	// generators are merged into this one. See include.go for the details.
+	Include []string `json:",omitempty"`
+
+	// IncludePolicy determines how definitions in this file are merged with
+	// ones of the same name from the included files: "merge", "replace" or "error".
+	IncludePolicy string `json:",omitempty"`
+
+	// Params holds named values that can be referenced as $name in expressions
+	// used for numeric fields of sources and generators. Clients can change
+	// them before building to retune the configuration.
+	Params map[string]float64 `json:",omitempty"`
+
 	// Seeds uses a name string as a key that can be referenced in SourceJSON
 	// structures and can have predefined seed values. When calling BuildSources(),
 	// a client may pass a function to build the actual RandomSource interface
@@ -155,11 +263,32 @@ type NoiseJSON struct {
 	// noise should be built.
 	Generators []GeneratorJSON
 
+	// AutoCache makes BuildSources() and BuildGenerators() put a Cache2D in front
+	// of every source and generator that is used by more than one generator, so
+	// that it's only calculated once per coordinate. The built generators are
+	// then no longer safe to use from multiple goroutines at once.
+	AutoCache bool `json:",omitempty"`
+
+	// Conflicts lists the definitions that overrode ones from included files
+	Conflicts []IncludeConflict `json:"-"`
+
+	// origins maps "Kind/Name" to the file that defined it while merging includes
+	origins map[string]string
+
+	// files lists all of the configuration files read by LoadNoiseJSONFile()
+	files []string
+
 	// builtSources are cached noise providers built after BuildSources()
 	builtSources map[string]NoiseyGet2D
 
 	// builtGenerators are cached noise generators built after BuildGenerators()
 	builtGenerators map[string]NoiseyGet2D
+
+	// builtSources3D are the built sources that also provide 3D noise
+	builtSources3D map[string]NoiseyGet3D
+
+	// builtGenerators3D are the generators built from the "3d" generator types
+	builtGenerators3D map[string]NoiseyGet3D
 }
 
 // NewNoiseJSON creates a new structure that can be used to save noise settings
@@ -171,12 +300,16 @@ func NewNoiseJSON() *NoiseJSON {
 
 	nj.builtSources = make(map[string]NoiseyGet2D)
 	nj.builtGenerators = make(map[string]NoiseyGet2D)
+	nj.builtSources3D = make(map[string]NoiseyGet3D)
+	nj.builtGenerators3D = make(map[string]NoiseyGet3D)
 
 	return nj
 }
 
 // LoadNoiseJSON unmarshals the JSON from the byte array and returns a NoiseJSON
-// object on success; error otherwise.
+// object on success; error otherwise. Any files in the "Include" list are
+// resolved relative to the current working directory; use LoadNoiseJSONFile()
+// to resolve them relative to the configuration file instead.
 func LoadNoiseJSON(bytes []byte) (*NoiseJSON, error) {
 	var cfg *NoiseJSON = NewNoiseJSON()
 	err := json.Unmarshal(bytes, cfg)
@@ -184,9 +317,29 @@ func LoadNoiseJSON(bytes []byte) (*NoiseJSON, error) {
 		return nil, fmt.Errorf("Unable to read json into the configuration structure.\n%v\n", err)
 	}
 
+	if len(cfg.Include) > 0 {
+		loader := newNoiseJSONLoader()
+		cfg, err = loader.load(bytes, ".", "")
+		if err != nil {
+			return nil, err
+		}
+		loader.finish(cfg)
+	}
+
 	return cfg, nil
 }
 
+// GetGenerator3D returns a cached generator NoiseyGet3D object built from one of
+// the "3d" generator types. This function Must be called after both BuildSources()
+// and BuildGenerators().
+func (cfg *NoiseJSON) GetGenerator3D(name string) NoiseyGet3D {
+	s, ok := cfg.builtGenerators3D[name]
+	if ok == false {
+		return nil
+	}
+	return s
+}
+
 // GetGenerator returns a cached generator NoiseyGet2D object. This function
 // Must be called after both BuildSources() and BuildGenerators().
 func (cfg *NoiseJSON) GetGenerator(name string) NoiseyGet2D {
@@ -214,11 +367,30 @@ func (cfg *NoiseJSON) SaveNoiseJSON() ([]byte, error) {
 // BuildSources takes a RandomSeedbuilder function as a parameter to create
 // the actual random number generators from the seed provided and then
 // creates the NoiseyGet2D interface objects based off of settings from
-// SourceJSON structures in NoiseJSON.Sources. This method should be
-// called before BuildGenerators().
+// SourceJSON structures in NoiseJSON.Sources. If seedBuilder is nil, PCG32
+// generators are used so that the noise is the same on every platform and Go
+// version. This method should be called before BuildGenerators().
 func (cfg *NoiseJSON) BuildSources(seedBuilder RandomSeedBuilder) error {
+	consumers := cfg.countConsumers()
+
 	// loop through all configured sources
 	for sourceName, source := range cfg.Sources {
+		// evaluate any expressions used for numeric fields
+		err := applyExpressions(&source, source.Expressions, cfg.Params)
+		if err != nil {
+			return fmt.Errorf("Source \"%s\" creation failed: %v.\n", sourceName, err)
+		}
+
+		// the primitive sources don't need any random numbers
+		primitive, err := buildPrimitiveSource(source)
+		if err != nil {
+			return fmt.Errorf("Source \"%s\" creation failed: %v.\n", sourceName, err)
+		}
+		if primitive != nil {
+			cfg.storeSource(sourceName, primitive, consumers)
+			continue
+		}
+
 		// get the random source by taking the referenced seed and calling
 		// the seedBuilder() function with it that was passed in.
 		seed, ok := cfg.Seeds[source.Seed]
@@ -227,28 +399,71 @@ func (cfg *NoiseJSON) BuildSources(seedBuilder RandomSeedBuilder) error {
 		}
 
 		// construct the random source using the passed in function if supplied;
-		// otherwise construct a default one.
+		// otherwise construct a portable PCG32 one.
 		var r RandomSource
 		if seedBuilder != nil {
 			r = seedBuilder(seed)
 		} else {
-			r = rand.New(rand.NewSource(int64(seed)))
+			r = NewPCG32(seed)
+		}
+
+		if len(source.Period) > 3 {
+			return fmt.Errorf("Source \"%s\" has more than three Period values.\n", sourceName)
+		}
+		var period Vec3i
+		for i, p := range source.Period {
+			if p < 0 {
+				return fmt.Errorf("Source \"%s\" has a negative Period value (%d).\n", sourceName, p)
+			}
+			switch i {
+			case 0:
+				period.X = p
+			case 1:
+				period.Y = p
+			case 2:
+				period.Z = p
+			}
 		}
 
 		var s NoiseyGet2D
 		switch source.SourceType {
 		case "perlin":
+			quality, err := source.noiseQuality(QualityAttenuated, true)
+			if err != nil {
+				return fmt.Errorf("Source \"%s\" creation failed: %v\n", sourceName, err)
+			}
 			p2d := NewPerlinGenerator(r)
+			p2d.Quality = quality
+			p2d.FullHash = source.FullHash
+			p2d.Period = period
 			s = NoiseyGet2D(&p2d)
+		case "classicperlin":
+			cp2d := NewClassicPerlinGenerator(r)
+			s = NoiseyGet2D(&cp2d)
+		case "value":
+			quality, err := source.noiseQuality(QualityStandard, false)
+			if err != nil {
+				return fmt.Errorf("Source \"%s\" creation failed: %v\n", sourceName, err)
+			}
+			v2d := NewValueNoiseGenerator(r)
+			v2d.Quality = quality
+			s = NoiseyGet2D(&v2d)
+		case "white":
+			w2d := NewWhiteNoiseGenerator(r)
+			s = NoiseyGet2D(&w2d)
 		case "opensimplex":
+			if period != (Vec3i{}) {
+				return fmt.Errorf("Source \"%s\" creation failed: opensimplex sources can't be periodic; use a perlin source.\n", sourceName)
+			}
 			os2d := NewOpenSimplexGenerator(r)
+			os2d.FullHash = source.FullHash
 			s = NoiseyGet2D(&os2d)
 		default:
 			return fmt.Errorf("Undefined source type (%s) for source %s.\n", source.SourceType, sourceName)
 		}
 
 		// store the result
-		cfg.builtSources[sourceName] = s
+		cfg.storeSource(sourceName, s, consumers)
 	}
 
 	return nil
@@ -258,11 +473,29 @@ func (cfg *NoiseJSON) BuildSources(seedBuilder RandomSeedBuilder) error {
 // in the GeneratorJSON objects in NoiseJSON.Gnerators. This method should be
 // called after BuildSources().
 func (cfg *NoiseJSON) BuildGenerators() error {
+	consumers := cfg.countConsumers()
+
 	// loop through all configured generators
 	for _, gen := range cfg.Generators {
 		var sourceArray []NoiseyGet2D
 		var genArray []NoiseyGet2D
 
+		// evaluate any expressions used for numeric fields
+		err := applyExpressions(&gen, gen.Expressions, cfg.Params)
+		if err != nil {
+			return fmt.Errorf("Generator \"%s\" creation failed: %v.\n", gen.Name, err)
+		}
+
+		// the "3d" generator types are built from the 3D sources and generators
+		if strings.HasSuffix(gen.GeneratorType, "3d") {
+			g3, err := cfg.buildGenerator3D(gen)
+			if err != nil {
+				return err
+			}
+			cfg.builtGenerators3D[gen.Name] = cfg.cacheShared3D(g3, "Generator/"+gen.Name, consumers)
+			continue
+		}
+
 		// build the array of sources and if one's not found, then return an error
 		if gen.Sources != nil {
 			sourceArray = make([]NoiseyGet2D, len(gen.Sources))
@@ -290,21 +523,256 @@ func (cfg *NoiseJSON) BuildGenerators() error {
 		var g NoiseyGet2D
 		switch gen.GeneratorType {
 		case "fBm2d":
+			if len(sourceArray) < 1 {
+				return fmt.Errorf("Generator \"%s\" creation failed: fBm2d requires 1 source.\n", gen.Name)
+			}
 			fbm := NewFBMGenerator2D(sourceArray[0], gen.Octaves, gen.Persistence, gen.Lacunarity, gen.Frequency)
 			g = NoiseyGet2D(&fbm)
 		case "select2d":
+			if len(genArray) < 3 {
+				return fmt.Errorf("Generator \"%s\" creation failed: select2d requires 3 generators.\n", gen.Name)
+			}
 			sel := NewSelect2D(genArray[0], genArray[1], genArray[2], gen.LowerBound, gen.UpperBound, gen.EdgeFalloff)
 			g = NoiseyGet2D(&sel)
 		case "scale2d":
+			if len(genArray) < 1 {
+				return fmt.Errorf("Generator \"%s\" creation failed: scale2d requires 1 generator.\n", gen.Name)
+			}
 			scale := NewScale2D(genArray[0], gen.Scale, gen.Bias, gen.Min, gen.Max)
 			g = NoiseyGet2D(&scale)
+		case "abs2d":
+			if len(genArray) < 1 {
+				return fmt.Errorf("Generator \"%s\" creation failed: abs2d requires 1 generator.\n", gen.Name)
+			}
+			abs := NewAbs2D(genArray[0])
+			g = NoiseyGet2D(&abs)
+		case "invert2d":
+			if len(genArray) < 1 {
+				return fmt.Errorf("Generator \"%s\" creation failed: invert2d requires 1 generator.\n", gen.Name)
+			}
+			inv := NewInvert2D(genArray[0])
+			g = NoiseyGet2D(&inv)
+		case "clamp2d":
+			if len(genArray) < 1 {
+				return fmt.Errorf("Generator \"%s\" creation failed: clamp2d requires 1 generator.\n", gen.Name)
+			}
+			clamp := NewClamp2D(genArray[0], gen.Min, gen.Max)
+			g = NoiseyGet2D(&clamp)
+		case "exponent2d":
+			if len(genArray) < 1 {
+				return fmt.Errorf("Generator \"%s\" creation failed: exponent2d requires 1 generator.\n", gen.Name)
+			}
+			exp := NewExponent2D(genArray[0], gen.Exponent)
+			g = NoiseyGet2D(&exp)
+		case "normalize2d":
+			if len(genArray) < 1 {
+				return fmt.Errorf("Generator \"%s\" creation failed: normalize2d requires 1 generator.\n", gen.Name)
+			}
+			norm, err := buildNormalize2D(gen, genArray[0])
+			if err != nil {
+				return err
+			}
+			g = NoiseyGet2D(&norm)
 		default:
 			return fmt.Errorf("Undefined generator type (%s) for generator %s.\n", gen.GeneratorType, gen.Name)
 		}
 
 		// store the result
-		cfg.builtGenerators[gen.Name] = g
+		cfg.builtGenerators[gen.Name] = cfg.cacheShared(g, "Generator/"+gen.Name, consumers)
 	}
 
 	return nil
 }
+
+// buildPrimitiveSource builds the source if it is one of the primitive
+// sources that don't use a seed; nil is returned for the other source types.
+func buildPrimitiveSource(source SourceJSON) (NoiseyGet2D, error) {
+	frequency := source.Frequency
+	if frequency == 0.0 {
+		frequency = 1.0
+	}
+
+	switch source.SourceType {
+	case "const":
+		c := NewConstGenerator(source.Value)
+		return NoiseyGet2D(&c), nil
+	case "checkerboard":
+		c := NewCheckerboardGenerator(frequency)
+		return NoiseyGet2D(&c), nil
+	case "cylinders":
+		c := NewCylindersGenerator(frequency)
+		return NoiseyGet2D(&c), nil
+	case "spheres":
+		s := NewSpheresGenerator(frequency)
+		return NoiseyGet2D(&s), nil
+	}
+	return nil, nil
+}
+
+// buildGenerator3D creates the NoiseyGet3D object for one of the "3d" generator types.
+func (cfg *NoiseJSON) buildGenerator3D(gen GeneratorJSON) (NoiseyGet3D, error) {
+	sourceArray := make([]NoiseyGet3D, len(gen.Sources))
+	for i, ss := range gen.Sources {
+		builtSource, ok := cfg.builtSources3D[ss]
+		if ok != true {
+			return nil, fmt.Errorf("Generator \"%s\" creation failed: couldn't find built 3d source \"%s\".\n", gen.Name, ss)
+		}
+		sourceArray[i] = builtSource
+	}
+
+	genArray := make([]NoiseyGet3D, len(gen.Generators))
+	for i, ss := range gen.Generators {
+		builtGen, ok := cfg.builtGenerators3D[ss]
+		if ok != true {
+			return nil, fmt.Errorf("Generator \"%s\" creation failed: couldn't find built 3d generator \"%s\".\n", gen.Name, ss)
+		}
+		genArray[i] = builtGen
+	}
+
+	// all of the 3d types but fBm3d take one or more generators
+	required := 1
+	switch gen.GeneratorType {
+	case "fBm3d":
+		if len(sourceArray) < 1 {
+			return nil, fmt.Errorf("Generator \"%s\" creation failed: fBm3d requires 1 source.\n", gen.Name)
+		}
+		required = 0
+	case "select3d":
+		required = 3
+	}
+	if len(genArray) < required {
+		return nil, fmt.Errorf("Generator \"%s\" creation failed: %s requires %d generators.\n", gen.Name, gen.GeneratorType, required)
+	}
+
+	switch gen.GeneratorType {
+	case "fBm3d":
+		fbm := NewFBMGenerator3D(sourceArray[0], gen.Octaves, gen.Persistence, gen.Lacunarity, gen.Frequency)
+		return NoiseyGet3D(&fbm), nil
+	case "select3d":
+		sel := NewSelect3D(genArray[0], genArray[1], genArray[2], gen.LowerBound, gen.UpperBound, gen.EdgeFalloff)
+		return NoiseyGet3D(&sel), nil
+	case "scale3d":
+		scale := NewScale3D(genArray[0], gen.Scale, gen.Bias, gen.Min, gen.Max)
+		return NoiseyGet3D(&scale), nil
+	case "abs3d":
+		abs := NewAbs3D(genArray[0])
+		return NoiseyGet3D(&abs), nil
+	case "invert3d":
+		inv := NewInvert3D(genArray[0])
+		return NoiseyGet3D(&inv), nil
+	case "clamp3d":
+		clamp := NewClamp3D(genArray[0], gen.Min, gen.Max)
+		return NoiseyGet3D(&clamp), nil
+	case "exponent3d":
+		exp := NewExponent3D(genArray[0], gen.Exponent)
+		return NoiseyGet3D(&exp), nil
+	case "normalize3d":
+		norm, err := buildNormalize3D(gen, genArray[0])
+		if err != nil {
+			return nil, err
+		}
+		return NoiseyGet3D(&norm), nil
+	}
+	return nil, fmt.Errorf("Undefined generator type (%s) for generator %s.\n", gen.GeneratorType, gen.Name)
+}
+
+// buildNormalize2D creates the module for a "normalize2d" generator.
+func buildNormalize2D(gen GeneratorJSON, src NoiseyGet2D) (Normalize2D, error) {
+	outMin, outMax := normalizeTarget(gen)
+	if gen.Samples <= 0 {
+		norm, err := NewNormalize2DFBM(src, outMin, outMax)
+		if err != nil {
+			return norm, fmt.Errorf("Generator \"%s\" creation failed: normalize2d needs an fBm generator or Samples.\n", gen.Name)
+		}
+		return norm, nil
+	}
+
+	b := Builder2DBounds{-64.0, -64.0, 64.0, 64.0}
+	if len(gen.SampleBounds) > 0 {
+		if len(gen.SampleBounds) != 4 {
+			return Normalize2D{}, fmt.Errorf("Generator \"%s\" creation failed: normalize2d needs 4 SampleBounds values.\n", gen.Name)
+		}
+		b = Builder2DBounds{gen.SampleBounds[0], gen.SampleBounds[1], gen.SampleBounds[2], gen.SampleBounds[3]}
+	}
+	norm, err := NewNormalize2DSampled(src, b, gen.Samples, NewPCG32(0), outMin, outMax)
+	if err != nil {
+		return norm, fmt.Errorf("Generator \"%s\" creation failed: normalize2d couldn't sample its range.\n%v\n", gen.Name, err)
+	}
+	return norm, nil
+}
+
+// buildNormalize3D creates the module for a "normalize3d" generator.
+func buildNormalize3D(gen GeneratorJSON, src NoiseyGet3D) (Normalize3D, error) {
+	outMin, outMax := normalizeTarget(gen)
+	if gen.Samples <= 0 {
+		norm, err := NewNormalize3DFBM(src, outMin, outMax)
+		if err != nil {
+			return norm, fmt.Errorf("Generator \"%s\" creation failed: normalize3d needs an fBm3d generator or Samples.\n", gen.Name)
+		}
+		return norm, nil
+	}
+
+	min, max := Vec3f{-64.0, -64.0, -64.0}, Vec3f{64.0, 64.0, 64.0}
+	if len(gen.SampleBounds) > 0 {
+		if len(gen.SampleBounds) != 6 {
+			return Normalize3D{}, fmt.Errorf("Generator \"%s\" creation failed: normalize3d needs 6 SampleBounds values.\n", gen.Name)
+		}
+		sb := gen.SampleBounds
+		min, max = Vec3f{sb[0], sb[1], sb[2]}, Vec3f{sb[3], sb[4], sb[5]}
+	}
+	norm, err := NewNormalize3DSampled(src, min, max, gen.Samples, NewPCG32(0), outMin, outMax)
+	if err != nil {
+		return norm, fmt.Errorf("Generator \"%s\" creation failed: normalize3d couldn't sample its range.\n%v\n", gen.Name, err)
+	}
+	return norm, nil
+}
+
+// normalizeTarget returns the range normalize generators map to: Min..Max,
+// or -1..1 if both are 0.
+func normalizeTarget(gen GeneratorJSON) (float64, float64) {
+	if gen.Min == 0.0 && gen.Max == 0.0 {
+		return -1.0, 1.0
+	}
+	return gen.Min, gen.Max
+}
+
+// storeSource stores the built source, and its 3D interface if it has one.
+func (cfg *NoiseJSON) storeSource(name string, s NoiseyGet2D, consumers map[string]int) {
+	cfg.builtSources[name] = cfg.cacheShared(s, "Source/"+name, consumers)
+	if s3, ok := s.(NoiseyGet3D); ok {
+		cfg.builtSources3D[name] = cfg.cacheShared3D(s3, "Source/"+name, consumers)
+	}
+}
+
+// countConsumers returns the number of generators using each source and
+// generator, keyed by "Source/name" or "Generator/name".
+func (cfg *NoiseJSON) countConsumers() map[string]int {
+	consumers := make(map[string]int)
+	for _, gen := range cfg.Generators {
+		for _, name := range gen.Sources {
+			consumers["Source/"+name]++
+		}
+		for _, name := range gen.Generators {
+			consumers["Generator/"+name]++
+		}
+	}
+	return consumers
+}
+
+// cacheShared wraps g in a Cache2D if AutoCache is set and g has more than one consumer.
+func (cfg *NoiseJSON) cacheShared(g NoiseyGet2D, key string, consumers map[string]int) NoiseyGet2D {
+	if !cfg.AutoCache || consumers[key] < 2 {
+		return g
+	}
+	cache := NewCache2D(g)
+	return NoiseyGet2D(&cache)
+}
+
+// cacheShared3D wraps g in a Cache3D if AutoCache is set and g has more than one consumer.
+func (cfg *NoiseJSON) cacheShared3D(g NoiseyGet3D, key string, consumers map[string]int) NoiseyGet3D {
+	if !cfg.AutoCache || consumers[key] < 2 {
+		return g
+	}
+	cache := NewCache3D(g)
+	return NoiseyGet3D(&cache)
+}
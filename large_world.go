@@ -0,0 +1,97 @@
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+/*
+
+This module contains the helpers for using PerlinGenerator and OpenSimplexGenerator
+in large, streaming worlds far away from the origin.
+
+Two things go wrong at large coordinates:
+
+	* The permutation tables only have 256 entries, so the lattice coordinates
+	  get masked with 0xFF and the noise repeats every 256 units. Setting
+	  FullHash on the generator hashes the full 64 bit lattice coordinates
+	  instead, so the noise never repeats. This changes the output, so it's
+	  off by default to keep existing worlds the same.
+
+	* A float64 has 53 bits of mantissa, so the bigger the coordinate, the fewer
+	  bits are left for the position inside of a lattice cell. The Get2DSplit and
+	  Get3DSplit methods take the coordinate split into an integer part and a
+	  fractional part and never combine the two into a single float64, so the
+	  fraction keeps its full precision no matter how far the integer part is
+	  from the origin.
+
+The large-world mode is to use both together, keeping world positions as an
+integer cell plus an offset inside that cell (e.g. chunk coordinates times the
+chunk size plus the local position):
+
+  perlin := noisey.NewPerlinGeneratorSeed(1)
+  perlin.FullHash = true
+
+  // sample the world position (worldX + 0.25, worldY + 0.5) where worldX and
+  // worldY are int64 values like 123456789012
+  v := perlin.Get2DSplit(worldX, worldY, 0.25, 0.5)
+
+Any frequency scaling has to be done before splitting the coordinate. For
+example, with N world units per lattice cell, the world position ix+fx splits
+into the integer part ix/N and the fraction (ix%N + fx)/N, using floored
+division and modulo for negative positions.
+
+*/
+
+import (
+	"math"
+)
+
+// NoiseyGet2DSplit is an interface for sources that can get noise from a
+// coordinate split into integer and fractional parts.
+type NoiseyGet2DSplit interface {
+	Get2DSplit(ix, iy int64, fx, fy float64) float64
+}
+
+// NoiseyGet3DSplit is an interface for sources that can get noise from a
+// coordinate split into integer and fractional parts.
+type NoiseyGet3DSplit interface {
+	Get3DSplit(ix, iy, iz int64, fx, fy, fz float64) float64
+}
+
+// splitLattice returns the integer lattice coordinate and the fraction in 0..1
+// of the coordinate i+f.
+func splitLattice(i int64, f float64) (int, float64) {
+	floored := math.Floor(f)
+	return int(i + int64(floored)), f - floored
+}
+
+// splitProduct returns i*c as an integer part and a remainder in 0..1 so
+// that the remainder keeps full precision even if i is huge.
+func splitProduct(i int64, c float64) (int64, float64) {
+	hi := float64(i) * c
+	lo := math.FMA(float64(i), c, -hi) // the rounding error of hi
+	floored := math.Floor(hi)
+	return int64(floored), (hi - floored) + lo
+}
+
+// permutationHashSeed derives a seed for the full lattice hash from a
+// permutation table so that sources built from different seeds differ.
+func permutationHashSeed(perm []int) uint64 {
+	var h uint64
+	for _, v := range perm {
+		h = mixHash64(h ^ uint64(v))
+	}
+	return h
+}
+
+// hashLattice2 hashes the full 2D lattice coordinate with the seed.
+func hashLattice2(seed uint64, x, y int) uint64 {
+	h := mixHash64(seed ^ uint64(x))
+	return mixHash64(h ^ uint64(y))
+}
+
+// hashLattice3 hashes the full 3D lattice coordinate with the seed.
+func hashLattice3(seed uint64, x, y, z int) uint64 {
+	h := mixHash64(seed ^ uint64(x))
+	h = mixHash64(h ^ uint64(y))
+	return mixHash64(h ^ uint64(z))
+}
@@ -0,0 +1,142 @@
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+type largeWorldSource interface {
+	NoiseyGet2D
+	NoiseyGet3D
+	NoiseyGet2DSplit
+	NoiseyGet3DSplit
+}
+
+type largeWorldCase struct {
+	name     string
+	src      largeWorldSource
+	fullHash bool
+	simplex  bool
+}
+
+// largeWorldCases returns the split sources that get tested, with and without FullHash.
+func largeWorldCases() []largeWorldCase {
+	var cases []largeWorldCase
+	for _, fullHash := range []bool{false, true} {
+		for _, q := range []NoiseQuality{QualityAttenuated, QualityFast, QualityStandard, QualityBest} {
+			pg := NewPerlinGeneratorSeed(1)
+			pg.Quality = q
+			pg.FullHash = fullHash
+			name := fmt.Sprintf("perlin quality %d fullhash %v", q, fullHash)
+			cases = append(cases, largeWorldCase{name, &pg, fullHash, false})
+		}
+		osg := NewOpenSimplexGeneratorSeed(1)
+		osg.FullHash = fullHash
+		name := fmt.Sprintf("opensimplex fullhash %v", fullHash)
+		cases = append(cases, largeWorldCase{name, &osg, fullHash, true})
+	}
+	return cases
+}
+
+func TestSplitMatchesGet(t *testing.T) {
+	// float64(i)+f is exact for all of these, so both paths see the same coordinate
+	ints := []int64{-1000, -3, -1, 0, 1, 7, 1 << 20, -(1 << 30), 1 << 40}
+	fracs := []float64{0, 0.25, 0.5, 0.875, -0.375, 1.25}
+	for _, c := range largeWorldCases() {
+		name, src := c.name, c.src
+		for n, i := range ints {
+			j := ints[(n+3)%len(ints)]
+
+			// perlin splits the same way Get2D floors, so they match exactly;
+			// opensimplex stretches the whole coordinate in Get2D, which loses
+			// precision as the coordinate grows
+			tolerance := 0.0
+			if c.simplex {
+				magnitude := math.Max(math.Abs(float64(i)), math.Abs(float64(j)))
+				tolerance = 1e-9 * (1 + magnitude/(1<<20))
+			}
+			for m, f := range fracs {
+				g := fracs[(m+1)%len(fracs)]
+				split := src.Get2DSplit(i, j, f, g)
+				plain := src.Get2D(float64(i)+f, float64(j)+g)
+				if math.Abs(split-plain) > tolerance {
+					t.Errorf("%s: Get2DSplit(%d, %d, %v, %v) = %v, Get2D = %v", name, i, j, f, g, split, plain)
+				}
+
+				// a z fraction of 1/16 keeps the point off the faces between
+				// the opensimplex regions, where 3D opensimplex has tiny jumps
+				split = src.Get3DSplit(i, j, -i, f, g, 0.0625)
+				plain = src.Get3D(float64(i)+f, float64(j)+g, float64(-i)+0.0625)
+				if math.Abs(split-plain) > tolerance {
+					t.Errorf("%s: Get3DSplit(%d, %d, %d, %v, %v, 0.0625) = %v, Get3D = %v", name, i, j, -i, f, g, split, plain)
+				}
+			}
+		}
+	}
+}
+
+func TestSplitContinuousAtLargeBoundaries(t *testing.T) {
+	const eps = 1e-9
+	boundaries := []int64{1 << 31, 1 << 32, -(1 << 31), -(1 << 32)}
+	for _, c := range largeWorldCases() {
+		name, src := c.name, c.src
+		for _, b := range boundaries {
+			for _, f := range []float64{0.1, 0.45, 0.8} {
+				// approach the boundary from below on each axis
+				below := src.Get2DSplit(b-1, 5, 1-eps, f)
+				at := src.Get2DSplit(b, 5, 0, f)
+				if math.Abs(below-at) > 1e-6 {
+					t.Errorf("%s: 2D jump of %v across x=%d", name, math.Abs(below-at), b)
+				}
+				below = src.Get2DSplit(5, b-1, f, 1-eps)
+				at = src.Get2DSplit(5, b, f, 0)
+				if math.Abs(below-at) > 1e-6 {
+					t.Errorf("%s: 2D jump of %v across y=%d", name, math.Abs(below-at), b)
+				}
+				below = src.Get3DSplit(b, b, b-1, f, f, 1-eps)
+				at = src.Get3DSplit(b, b, b, f, f, 0)
+				if math.Abs(below-at) > 1e-6 {
+					t.Errorf("%s: 3D jump of %v across z=%d", name, math.Abs(below-at), b)
+				}
+
+				// a fraction past 1 lands in the next cell
+				if v, w := src.Get2DSplit(b-1, 5, 1, f), src.Get2DSplit(b, 5, 0, f); v != w {
+					t.Errorf("%s: Get2DSplit(%d, 5, 1, %v) = %v, expected %v", name, b-1, f, v, w)
+				}
+			}
+		}
+	}
+}
+
+func TestFullHashDoesNotRepeat(t *testing.T) {
+	for _, c := range largeWorldCases() {
+		name, src := c.name, c.src
+		for _, base := range []int64{0, 1 << 32} {
+			same := 0
+			total := 0
+			for i := int64(0); i < 16; i++ {
+				for _, f := range []float64{0.3, 0.7} {
+					x, y := base+i*3, -i*5
+					v := src.Get2DSplit(x, y, f, f)
+					if v == src.Get2DSplit(x+256, y, f, f) && v == src.Get2DSplit(x, y+256, f, f) &&
+						src.Get3DSplit(x, y, i, f, f, f) == src.Get3DSplit(x, y, i+256, f, f, f) {
+						same++
+					}
+					total++
+				}
+			}
+			if c.fullHash && same > 0 {
+				t.Errorf("%s: %d of %d values repeat with a period of 256 at %d", name, same, total, base)
+			}
+			// the skewed opensimplex lattice doesn't line up with the axes, so
+			// only perlin repeats along them without FullHash
+			if !c.fullHash && !c.simplex && same != total {
+				t.Errorf("%s: only %d of %d values repeat with a period of 256 without FullHash", name, same, total)
+			}
+		}
+	}
+}
@@ -0,0 +1,320 @@
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+/*
+
+This module turns the values of a Builder2D into a terrain mesh and writes
+meshes as Wavefront OBJ or binary STL files for DCC tools:
+
+  builder.Build()
+  mesh := builder.Mesh(1.0, 32.0, 0.0)
+  mesh.WriteOBJ(objFile)
+  mesh.WriteSTL(stlFile)
+
+Every value becomes a vertex at (x * spacing, value * heightScale, y * spacing),
+so the mesh is Y up and the rows of the builder run along Z. Vertex normals
+come from the slope of the heightmap and the UVs map the whole map to 0..1,
+with V = 1 at the first row to match the orientation of Builder2D.Image().
+
+With a positive tolerance, flat regions get decimated: square blocks of cells
+whose heights are all within tolerance of each other are drawn as a fan of
+triangles around their center instead of two triangles per cell. The fans
+keep every vertex along the edge of the block, so the mesh never gets cracks
+or T-junctions where blocks of different sizes meet.
+
+*/
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+const (
+	// the largest block of cells that decimation merges
+	meshMaxBlock = 64
+)
+
+// Mesh is an indexed triangle mesh.
+type Mesh struct {
+	Vertices []Vec3f  // the positions of the vertices
+	Normals  []Vec3f  // the normal of each vertex; may be empty
+	UVs      []Vec2f  // the texture coordinate of each vertex; may be empty
+	Indices  []uint32 // three vertex indices per triangle, counter-clockwise seen from the front
+}
+
+// TriangleCount returns the number of triangles in the mesh.
+func (m *Mesh) TriangleCount() int {
+	return len(m.Indices) / 3
+}
+
+// WriteOBJ writes the mesh to w in the Wavefront OBJ format, including the
+// normals and UVs if there is one for each vertex.
+func (m *Mesh) WriteOBJ(w io.Writer) error {
+	out := bufio.NewWriter(w)
+	hasNormals := len(m.Normals) == len(m.Vertices) && len(m.Normals) > 0
+	hasUVs := len(m.UVs) == len(m.Vertices) && len(m.UVs) > 0
+
+	fmt.Fprintf(out, "# noisey mesh: %d vertices, %d triangles\n", len(m.Vertices), m.TriangleCount())
+	for _, v := range m.Vertices {
+		fmt.Fprintf(out, "v %g %g %g\n", float32(v.X), float32(v.Y), float32(v.Z))
+	}
+	if hasUVs {
+		for _, uv := range m.UVs {
+			fmt.Fprintf(out, "vt %g %g\n", float32(uv.X), float32(uv.Y))
+		}
+	}
+	if hasNormals {
+		for _, n := range m.Normals {
+			fmt.Fprintf(out, "vn %g %g %g\n", float32(n.X), float32(n.Y), float32(n.Z))
+		}
+	}
+
+	for i := 0; i+2 < len(m.Indices); i += 3 {
+		out.WriteString("f")
+		for _, index := range m.Indices[i : i+3] {
+			// OBJ indices start at 1
+			v := index + 1
+			switch {
+			case hasUVs && hasNormals:
+				fmt.Fprintf(out, " %d/%d/%d", v, v, v)
+			case hasUVs:
+				fmt.Fprintf(out, " %d/%d", v, v)
+			case hasNormals:
+				fmt.Fprintf(out, " %d//%d", v, v)
+			default:
+				fmt.Fprintf(out, " %d", v)
+			}
+		}
+		out.WriteString("\n")
+	}
+
+	return out.Flush()
+}
+
+// WriteSTL writes the mesh to w in the binary STL format. STL has no normals
+// per vertex or UVs, so each triangle gets the normal of its face.
+func (m *Mesh) WriteSTL(w io.Writer) error {
+	out := bufio.NewWriter(w)
+
+	var header [80]byte
+	copy(header[:], "noisey mesh")
+	out.Write(header[:])
+	binary.Write(out, binary.LittleEndian, uint32(m.TriangleCount()))
+
+	var facet [12]float32
+	for i := 0; i+2 < len(m.Indices); i += 3 {
+		a := m.Vertices[m.Indices[i]]
+		b := m.Vertices[m.Indices[i+1]]
+		c := m.Vertices[m.Indices[i+2]]
+		n := faceNormal(a, b, c)
+		facet = [12]float32{
+			float32(n.X), float32(n.Y), float32(n.Z),
+			float32(a.X), float32(a.Y), float32(a.Z),
+			float32(b.X), float32(b.Y), float32(b.Z),
+			float32(c.X), float32(c.Y), float32(c.Z),
+		}
+		binary.Write(out, binary.LittleEndian, facet)
+		binary.Write(out, binary.LittleEndian, uint16(0))
+	}
+
+	return out.Flush()
+}
+
+// faceNormal returns the unit normal of the triangle abc, or a zero vector
+// if the triangle is degenerate.
+func faceNormal(a, b, c Vec3f) Vec3f {
+	u := Vec3f{b.X - a.X, b.Y - a.Y, b.Z - a.Z}
+	v := Vec3f{c.X - a.X, c.Y - a.Y, c.Z - a.Z}
+	return normalize3f(Vec3f{u.Y*v.Z - u.Z*v.Y, u.Z*v.X - u.X*v.Z, u.X*v.Y - u.Y*v.X})
+}
+
+// normalize3f returns v scaled to unit length, or a zero vector if v has no length.
+func normalize3f(v Vec3f) Vec3f {
+	l := math.Sqrt(v.X*v.X + v.Y*v.Y + v.Z*v.Z)
+	if l == 0.0 {
+		return Vec3f{}
+	}
+	return Vec3f{v.X / l, v.Y / l, v.Z / l}
+}
+
+/* ------------------------------------------------------------------------- */
+
+// heightmapMesher builds the mesh of a heightmap.
+type heightmapMesher struct {
+	values      []float64 // the heights, including the border
+	sizeX       int       // the number of values along X, not counting the border
+	sizeZ       int       // the number of values along Z, not counting the border
+	border      int       // the number of extra values around the heightmap used for normals
+	origin      Vec2f     // the X and Z position of the first value
+	spacing     float64
+	heightScale float64
+	tolerance   float64
+	mesh        *Mesh
+	vertexIndex []int // the mesh vertex of each value, or -1
+}
+
+// Mesh triangulates the Values as a heightmap with spacing between
+// neighbouring values and the values multiplied by heightScale. If tolerance
+// is positive, blocks of cells whose heights differ by at most tolerance (after
+// scaling) are merged into fewer triangles. Builders smaller than 2x2 give
+// an empty mesh.
+func (b *Builder2D) Mesh(spacing float64, heightScale float64, tolerance float64) *Mesh {
+	return meshHeightmap(b.Values, b.Width, b.Height, 0, Vec2f{}, spacing, heightScale, tolerance)
+}
+
+// meshHeightmap triangulates a heightmap of width x height values surrounded
+// by border extra values on every side, which only get used for the normals.
+// The first value that isn't part of the border is placed at origin.
+func meshHeightmap(values []float64, width, height, border int, origin Vec2f, spacing, heightScale, tolerance float64) *Mesh {
+	hm := &heightmapMesher{
+		values:      values,
+		sizeX:       width,
+		sizeZ:       height,
+		border:      border,
+		origin:      origin,
+		spacing:     spacing,
+		heightScale: heightScale,
+		tolerance:   tolerance,
+		mesh:        new(Mesh),
+		vertexIndex: make([]int, width*height),
+	}
+	for i := range hm.vertexIndex {
+		hm.vertexIndex[i] = -1
+	}
+
+	cellsW, cellsH := width-1, height-1
+	if cellsW < 1 || cellsH < 1 {
+		return hm.mesh
+	}
+
+	if tolerance <= 0.0 {
+		for z := 0; z < cellsH; z++ {
+			for x := 0; x < cellsW; x++ {
+				hm.cell(x, z)
+			}
+		}
+		return hm.mesh
+	}
+
+	for z := 0; z < cellsH; z += meshMaxBlock {
+		for x := 0; x < cellsW; x += meshMaxBlock {
+			hm.block(x, z, meshMaxBlock)
+		}
+	}
+	return hm.mesh
+}
+
+// height returns the scaled height at the value (x, z); coordinates down to
+// -border and up to the size plus border reach into the border.
+func (hm *heightmapMesher) height(x, z int) float64 {
+	stride := hm.sizeX + 2*hm.border
+	return hm.values[(z+hm.border)*stride+x+hm.border] * hm.heightScale
+}
+
+// vertex returns the index of the mesh vertex for the value (x, z), adding it
+// to the mesh the first time.
+func (hm *heightmapMesher) vertex(x, z int) uint32 {
+	w, h := hm.sizeX, hm.sizeZ
+	if i := hm.vertexIndex[z*w+x]; i >= 0 {
+		return uint32(i)
+	}
+
+	// the slope comes from the neighbouring values, one sided at the edges
+	x0, x1 := maxInt(x-1, -hm.border), minInt(x+1, w-1+hm.border)
+	z0, z1 := maxInt(z-1, -hm.border), minInt(z+1, h-1+hm.border)
+	dx := (hm.height(x1, z) - hm.height(x0, z)) / (float64(x1-x0) * hm.spacing)
+	dz := (hm.height(x, z1) - hm.height(x, z0)) / (float64(z1-z0) * hm.spacing)
+
+	m := hm.mesh
+	i := len(m.Vertices)
+	m.Vertices = append(m.Vertices, Vec3f{hm.origin.X + float64(x)*hm.spacing, hm.height(x, z), hm.origin.Y + float64(z)*hm.spacing})
+	m.Normals = append(m.Normals, normalize3f(Vec3f{-dx, 1.0, -dz}))
+	m.UVs = append(m.UVs, Vec2f{float64(x) / float64(w-1), 1.0 - float64(z)/float64(h-1)})
+	hm.vertexIndex[z*w+x] = i
+	return uint32(i)
+}
+
+// triangle adds the triangle between three values to the mesh.
+func (hm *heightmapMesher) triangle(ax, az, bx, bz, cx, cz int) {
+	hm.mesh.Indices = append(hm.mesh.Indices, hm.vertex(ax, az), hm.vertex(bx, bz), hm.vertex(cx, cz))
+}
+
+// cell adds the two triangles of the cell with its first corner at (x, z).
+func (hm *heightmapMesher) cell(x, z int) {
+	hm.triangle(x, z, x, z+1, x+1, z)
+	hm.triangle(x+1, z, x, z+1, x+1, z+1)
+}
+
+// block adds the cells of the size x size block starting at (x, z), merging
+// them if the block is flat or splitting it in four otherwise.
+func (hm *heightmapMesher) block(x, z, size int) {
+	cellsW, cellsH := hm.sizeX-1, hm.sizeZ-1
+	if x >= cellsW || z >= cellsH {
+		return
+	}
+	if size == 1 {
+		hm.cell(x, z)
+		return
+	}
+	if x+size <= cellsW && z+size <= cellsH && hm.flat(x, z, size) {
+		hm.fan(x, z, size)
+		return
+	}
+
+	half := size / 2
+	hm.block(x, z, half)
+	hm.block(x+half, z, half)
+	hm.block(x, z+half, half)
+	hm.block(x+half, z+half, half)
+}
+
+// flat returns true if the heights of the block differ by at most the tolerance.
+func (hm *heightmapMesher) flat(x, z, size int) bool {
+	low, high := math.Inf(1), math.Inf(-1)
+	for j := z; j <= z+size; j++ {
+		for i := x; i <= x+size; i++ {
+			h := hm.height(i, j)
+			low = math.Min(low, h)
+			high = math.Max(high, h)
+		}
+	}
+	return high-low <= hm.tolerance
+}
+
+// fan adds the block as triangles from its center to every value on its edge.
+func (hm *heightmapMesher) fan(x, z, size int) {
+	cx, cz := x+size/2, z+size/2
+
+	// walk the edge: down the first column, along the last row, up the last
+	// column and back along the first row
+	px, pz := x, z
+	steps := []Vec2i{{0, 1}, {1, 0}, {0, -1}, {-1, 0}}
+	for _, step := range steps {
+		for k := 0; k < size; k++ {
+			nx, nz := px+step.X, pz+step.Y
+			hm.triangle(px, pz, nx, nz, cx, cz)
+			px, pz = nx, nz
+		}
+	}
+}
+
+// minInt returns the smaller of a and b.
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// maxInt returns the larger of a and b.
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,88 @@
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// checkHeightmapMesh makes sure every triangle faces up and that every inner
+// edge is shared by exactly two triangles, so the mesh has no cracks.
+func checkHeightmapMesh(t *testing.T, m *Mesh, name string) {
+	edges := make(map[[2]uint32]int)
+	for i := 0; i < len(m.Indices); i += 3 {
+		tri := m.Indices[i : i+3]
+		n := faceNormal(m.Vertices[tri[0]], m.Vertices[tri[1]], m.Vertices[tri[2]])
+		if n.Y <= 0.0 {
+			t.Fatalf("%s: triangle %d doesn't face up: %v", name, i/3, n)
+		}
+		for k := 0; k < 3; k++ {
+			a, b := tri[k], tri[(k+1)%3]
+			if a > b {
+				a, b = b, a
+			}
+			edges[[2]uint32{a, b}]++
+		}
+	}
+
+	for e, count := range edges {
+		va, vb := m.Vertices[e[0]], m.Vertices[e[1]]
+		onBorder := (va.X == vb.X && (va.X == 0.0 || va.X == 15.0)) || (va.Z == vb.Z && (va.Z == 0.0 || va.Z == 15.0))
+		if (onBorder && count != 1) || (!onBorder && count != 2) {
+			t.Fatalf("%s: edge %v-%v is used by %d triangles", name, va, vb, count)
+		}
+	}
+}
+
+func TestHeightmapMesh(t *testing.T) {
+	// a flat map with a bump in one corner
+	b := NewBuilder2D(nil, 16, 16)
+	b.Values[2*16+3] = 1.0
+
+	full := b.Mesh(1.0, 2.0, 0.0)
+	if full.TriangleCount() != 15*15*2 || len(full.Vertices) != 16*16 || len(full.UVs) != 16*16 || len(full.Normals) != 16*16 {
+		t.Errorf("Mesh has %d triangles and %d vertices", full.TriangleCount(), len(full.Vertices))
+	}
+	checkHeightmapMesh(t, full, "full mesh")
+	for _, v := range full.Vertices {
+		if v.Y != 0.0 && (v.X != 3.0 || v.Y != 2.0 || v.Z != 2.0) {
+			t.Errorf("Mesh put the bump at %v", v)
+		}
+	}
+
+	decimated := b.Mesh(1.0, 2.0, 0.01)
+	if decimated.TriangleCount() >= full.TriangleCount() {
+		t.Errorf("Decimation didn't remove triangles: %d", decimated.TriangleCount())
+	}
+	checkHeightmapMesh(t, decimated, "decimated mesh")
+}
+
+func TestMeshWriters(t *testing.T) {
+	b := NewBuilder2D(nil, 3, 2)
+	m := b.Mesh(0.5, 1.0, 0.0)
+
+	var obj bytes.Buffer
+	err := m.WriteOBJ(&obj)
+	if err != nil {
+		t.Fatalf("WriteOBJ failed: %v", err)
+	}
+	text := obj.String()
+	if strings.Count(text, "\nv ") != 6 || strings.Count(text, "\nvt ") != 6 || strings.Count(text, "\nvn ") != 6 || strings.Count(text, "\nf ") != 4 {
+		t.Errorf("WriteOBJ wrote an unexpected file:\n%s", text)
+	}
+	if !strings.Contains(text, "\nf 1/1/1 2/2/2 3/3/3\n") {
+		t.Errorf("WriteOBJ didn't write the first face as expected:\n%s", text)
+	}
+
+	var stl bytes.Buffer
+	err = m.WriteSTL(&stl)
+	if err != nil {
+		t.Fatalf("WriteSTL failed: %v", err)
+	}
+	if stl.Len() != 84+50*4 {
+		t.Errorf("WriteSTL wrote %d bytes", stl.Len())
+	}
+}
@@ -0,0 +1,175 @@
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+/*
+
+This module contains the simple modifier modules that transform the value of
+a single source:
+
+  * Abs2D/Abs3D - the absolute value of the source
+  * Invert2D/Invert3D - the negated value of the source
+  * Clamp2D/Clamp3D - the value of the source clamped to Min..Max
+  * Exponent2D/Exponent3D - the value of the source raised to a power
+
+They can be chained to compose transformations explicitly instead of setting
+up a Scale2D module that only does part of its job.
+
+*/
+
+import "math"
+
+// Abs2D is a module that returns the absolute value of Source.
+type Abs2D struct {
+	Source NoiseyGet2D // the noise that the abs module uses
+}
+
+// NewAbs2D creates a new abs 2d module.
+func NewAbs2D(src NoiseyGet2D) (abs Abs2D) {
+	abs.Source = src
+	return
+}
+
+// Get2D calculates the absolute noise value
+func (abs *Abs2D) Get2D(x float64, y float64) float64 {
+	return math.Abs(abs.Source.Get2D(x, y))
+}
+
+// Abs3D is a module that returns the absolute value of Source.
+type Abs3D struct {
+	Source NoiseyGet3D // the noise that the abs module uses
+}
+
+// NewAbs3D creates a new abs 3d module.
+func NewAbs3D(src NoiseyGet3D) (abs Abs3D) {
+	abs.Source = src
+	return
+}
+
+// Get3D calculates the absolute noise value
+func (abs *Abs3D) Get3D(x float64, y float64, z float64) float64 {
+	return math.Abs(abs.Source.Get3D(x, y, z))
+}
+
+// Invert2D is a module that returns the negated value of Source.
+type Invert2D struct {
+	Source NoiseyGet2D // the noise that the invert module uses
+}
+
+// NewInvert2D creates a new invert 2d module.
+func NewInvert2D(src NoiseyGet2D) (inv Invert2D) {
+	inv.Source = src
+	return
+}
+
+// Get2D calculates the inverted noise value
+func (inv *Invert2D) Get2D(x float64, y float64) float64 {
+	return -inv.Source.Get2D(x, y)
+}
+
+// Invert3D is a module that returns the negated value of Source.
+type Invert3D struct {
+	Source NoiseyGet3D // the noise that the invert module uses
+}
+
+// NewInvert3D creates a new invert 3d module.
+func NewInvert3D(src NoiseyGet3D) (inv Invert3D) {
+	inv.Source = src
+	return
+}
+
+// Get3D calculates the inverted noise value
+func (inv *Invert3D) Get3D(x float64, y float64, z float64) float64 {
+	return -inv.Source.Get3D(x, y, z)
+}
+
+// Clamp2D is a module that clamps the value of Source to Min..Max.
+type Clamp2D struct {
+	Source NoiseyGet2D // the noise that the clamp module uses
+	Min    float64     // the minimum value to return
+	Max    float64     // the maximum value to return
+}
+
+// NewClamp2D creates a new clamp 2d module.
+func NewClamp2D(src NoiseyGet2D, min float64, max float64) (clamp Clamp2D) {
+	clamp.Source = src
+	clamp.Min = min
+	clamp.Max = max
+	return
+}
+
+// Get2D calculates the clamped noise value
+func (clamp *Clamp2D) Get2D(x float64, y float64) float64 {
+	return clampValue(clamp.Source.Get2D(x, y), clamp.Min, clamp.Max)
+}
+
+// Clamp3D is a module that clamps the value of Source to Min..Max.
+type Clamp3D struct {
+	Source NoiseyGet3D // the noise that the clamp module uses
+	Min    float64     // the minimum value to return
+	Max    float64     // the maximum value to return
+}
+
+// NewClamp3D creates a new clamp 3d module.
+func NewClamp3D(src NoiseyGet3D, min float64, max float64) (clamp Clamp3D) {
+	clamp.Source = src
+	clamp.Min = min
+	clamp.Max = max
+	return
+}
+
+// Get3D calculates the clamped noise value
+func (clamp *Clamp3D) Get3D(x float64, y float64, z float64) float64 {
+	return clampValue(clamp.Source.Get3D(x, y, z), clamp.Min, clamp.Max)
+}
+
+// clampValue clamps v to min..max the same way Scale2D does.
+func clampValue(v float64, min float64, max float64) float64 {
+	v = math.Max(min, v)
+	v = math.Min(max, v)
+	return v
+}
+
+// Exponent2D is a module that maps the value of Source from -1..1 to 0..1,
+// raises it to the power of Exponent and maps it back to -1..1.
+type Exponent2D struct {
+	Source   NoiseyGet2D // the noise that the exponent module uses
+	Exponent float64     // the power to raise the value to
+}
+
+// NewExponent2D creates a new exponent 2d module.
+func NewExponent2D(src NoiseyGet2D, exponent float64) (exp Exponent2D) {
+	exp.Source = src
+	exp.Exponent = exponent
+	return
+}
+
+// Get2D calculates the noise value raised to the power of Exponent
+func (exp *Exponent2D) Get2D(x float64, y float64) float64 {
+	return exponentValue(exp.Source.Get2D(x, y), exp.Exponent)
+}
+
+// Exponent3D is a module that maps the value of Source from -1..1 to 0..1,
+// raises it to the power of Exponent and maps it back to -1..1.
+type Exponent3D struct {
+	Source   NoiseyGet3D // the noise that the exponent module uses
+	Exponent float64     // the power to raise the value to
+}
+
+// NewExponent3D creates a new exponent 3d module.
+func NewExponent3D(src NoiseyGet3D, exponent float64) (exp Exponent3D) {
+	exp.Source = src
+	exp.Exponent = exponent
+	return
+}
+
+// Get3D calculates the noise value raised to the power of Exponent
+func (exp *Exponent3D) Get3D(x float64, y float64, z float64) float64 {
+	return exponentValue(exp.Source.Get3D(x, y, z), exp.Exponent)
+}
+
+// exponentValue applies the exponent like libnoise's Exponent module does.
+func exponentValue(v float64, exponent float64) float64 {
+	return math.Pow(math.Abs((v+1.0)/2.0), exponent)*2.0 - 1.0
+}
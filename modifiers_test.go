@@ -0,0 +1,71 @@
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+import (
+	"testing"
+)
+
+func TestModifiers(t *testing.T) {
+	c := NewConstGenerator(-0.5)
+
+	abs2 := NewAbs2D(&c)
+	abs3 := NewAbs3D(&c)
+	if abs2.Get2D(1, 2) != 0.5 || abs3.Get3D(1, 2, 3) != 0.5 {
+		t.Errorf("Abs modules returned the wrong value")
+	}
+
+	inv2 := NewInvert2D(&c)
+	inv3 := NewInvert3D(&c)
+	if inv2.Get2D(1, 2) != 0.5 || inv3.Get3D(1, 2, 3) != 0.5 {
+		t.Errorf("Invert modules returned the wrong value")
+	}
+
+	clamp2 := NewClamp2D(&c, -0.25, 0.25)
+	clamp3 := NewClamp3D(&c, -1.0, -0.75)
+	if clamp2.Get2D(1, 2) != -0.25 || clamp3.Get3D(1, 2, 3) != -0.75 {
+		t.Errorf("Clamp modules returned the wrong value")
+	}
+
+	// -0.5 maps to 0.25, squared is 0.0625 which maps back to -0.875
+	exp2 := NewExponent2D(&c, 2.0)
+	exp3 := NewExponent3D(&c, 2.0)
+	if exp2.Get2D(1, 2) != -0.875 || exp3.Get3D(1, 2, 3) != -0.875 {
+		t.Errorf("Exponent modules returned the wrong value")
+	}
+}
+
+func TestNoiseJSONModifiers(t *testing.T) {
+	json := []byte(`{
+		"Sources": {
+			"const": { "SourceType": "const", "Value": -0.5 }
+		},
+		"Generators": [
+			{ "Name": "base", "GeneratorType": "fBm2d", "Sources": [ "const" ], "Octaves": 1, "Frequency": 1.0 },
+			{ "Name": "abs", "GeneratorType": "abs2d", "Generators": [ "base" ] },
+			{ "Name": "invert", "GeneratorType": "invert2d", "Generators": [ "abs" ] },
+			{ "Name": "clamp", "GeneratorType": "clamp2d", "Generators": [ "invert" ], "Min": -0.25, "Max": 1.0 },
+			{ "Name": "exponent", "GeneratorType": "exponent2d", "Generators": [ "clamp" ], "Exponent": 2.0 }
+		]
+	}`)
+
+	cfg, err := LoadNoiseJSON(json)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cfg.BuildSources(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cfg.BuildGenerators()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// -0.5 -> 0.5 -> -0.5 -> -0.25 -> 0.375^2*2-1
+	expected := 0.375*0.375*2.0 - 1.0
+	if v := cfg.GetGenerator("exponent").Get2D(0.3, 0.7); v != expected {
+		t.Errorf("the modifier chain returned %v; expected %v", v, expected)
+	}
+}
@@ -70,6 +70,78 @@ func BenchmarkPerlin3D(b *testing.B) {
 	//fmt.Printf("\n\nPerlin resulting sum = %f\n", sum)
 }
 
+func BenchmarkClassicPerlin2D(b *testing.B) {
+	var sum float64 = 0
+	const benchSize = 100
+
+	// make a test generator seeded to 1
+	rngPerlin := rand.New(rand.NewSource(int64(1)))
+	perlin := NewClassicPerlinGenerator(rngPerlin)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for y := 0; y < benchSize; y++ {
+			for x := 0; x < benchSize; x++ {
+				sum += perlin.Get2D(float64(x), float64(y))
+			}
+		}
+	}
+}
+
+func BenchmarkClassicPerlin3D(b *testing.B) {
+	var sum float64 = 0
+	const benchSize = 100
+
+	// make a test generator seeded to 1
+	rngPerlin := rand.New(rand.NewSource(int64(1)))
+	perlin := NewClassicPerlinGenerator(rngPerlin)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for y := 0; y < benchSize; y++ {
+			for x := 0; x < benchSize; x++ {
+				sum += perlin.Get3D(float64(x), float64(y), 0.5)
+			}
+		}
+	}
+}
+
+func BenchmarkValueNoise2D(b *testing.B) {
+	var sum float64 = 0
+	const benchSize = 100
+
+	// make a test generator seeded to 1
+	rngValue := rand.New(rand.NewSource(int64(1)))
+	value := NewValueNoiseGenerator(rngValue)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for y := 0; y < benchSize; y++ {
+			for x := 0; x < benchSize; x++ {
+				sum += value.Get2D(float64(x)*0.1, float64(y)*0.1)
+			}
+		}
+	}
+}
+
+func BenchmarkWhiteNoise2D(b *testing.B) {
+	var sum float64 = 0
+	const benchSize = 100
+
+	// make a test generator seeded to 1
+	rngWhite := rand.New(rand.NewSource(int64(1)))
+	white := NewWhiteNoiseGenerator(rngWhite)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for y := 0; y < benchSize; y++ {
+			for x := 0; x < benchSize; x++ {
+				sum += white.Get2D(float64(x), float64(y))
+			}
+		}
+	}
+}
+
 func BenchmarkOpenSimplex2D(b *testing.B) {
 	var sum float64 = 0
 	const benchSize = 100
@@ -131,3 +203,167 @@ func BenchmarkOpenSimplex3D(b *testing.B) {
 	}
 	//	fmt.Printf("\n\nOpenSimplex resulting sum = %f\n", sum)
 }
+
+func BenchmarkFBM2DBuilder(b *testing.B) {
+	const benchSize = 100
+
+	// make a test generator seeded to 1 and hide the batch interface
+	perlin := NewPerlinGeneratorSeed(1)
+	fbm := NewFBMGenerator2D(getOnly{&perlin}, 4, 0.5, 2.0, 1.0)
+	builder := NewBuilder2D(getOnly{&fbm}, benchSize, benchSize)
+	builder.Bounds = Builder2DBounds{0.0, 0.0, benchSize, benchSize}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		builder.Build()
+	}
+}
+
+func BenchmarkFBM2DBuilderBatch(b *testing.B) {
+	const benchSize = 100
+
+	// make a test generator seeded to 1
+	perlin := NewPerlinGeneratorSeed(1)
+	fbm := NewFBMGenerator2D(&perlin, 4, 0.5, 2.0, 1.0)
+	builder := NewBuilder2D(&fbm, benchSize, benchSize)
+	builder.Bounds = Builder2DBounds{0.0, 0.0, benchSize, benchSize}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		builder.Build()
+	}
+}
+
+// the image benchmarks sample a few units of noise like the examples do, so
+// that neighbouring values share lattice cells in the lower octaves
+func BenchmarkFBM2DBuilderImage(b *testing.B) {
+	const benchSize = 100
+
+	// make a test generator seeded to 1 and hide the batch interface
+	perlin := NewPerlinGeneratorSeed(1)
+	fbm := NewFBMGenerator2D(getOnly{&perlin}, 4, 0.5, 2.0, 1.0)
+	builder := NewBuilder2D(getOnly{&fbm}, benchSize, benchSize)
+	builder.Bounds = Builder2DBounds{0.0, 0.0, 6.0, 6.0}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		builder.Build()
+	}
+}
+
+func BenchmarkFBM2DBuilderImageBatch(b *testing.B) {
+	const benchSize = 100
+
+	// make a test generator seeded to 1
+	perlin := NewPerlinGeneratorSeed(1)
+	fbm := NewFBMGenerator2D(&perlin, 4, 0.5, 2.0, 1.0)
+	builder := NewBuilder2D(&fbm, benchSize, benchSize)
+	builder.Bounds = Builder2DBounds{0.0, 0.0, 6.0, 6.0}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		builder.Build()
+	}
+}
+
+func BenchmarkPerlin2D32(b *testing.B) {
+	var sum float32 = 0
+	const benchSize = 100
+
+	// make a test generator seeded to 1
+	perlin := NewPerlinGenerator32Seed(1)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for y := 0; y < benchSize; y++ {
+			for x := 0; x < benchSize; x++ {
+				sum += perlin.Get2D32(float32(x), float32(y))
+			}
+		}
+	}
+}
+
+func BenchmarkPerlin3D32(b *testing.B) {
+	var sum float32 = 0
+	const benchSize = 100
+
+	// make a test generator seeded to 1
+	perlin := NewPerlinGenerator32Seed(1)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for y := 0; y < benchSize; y++ {
+			for x := 0; x < benchSize; x++ {
+				sum += perlin.Get3D32(float32(x), float32(y), 0.5)
+			}
+		}
+	}
+}
+
+func BenchmarkOpenSimplex2D32(b *testing.B) {
+	var sum float32 = 0
+	const benchSize = 100
+
+	// make a test generator seeded to 1
+	openSimplex := NewOpenSimplexGenerator32Seed(1)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for y := 0; y < benchSize; y++ {
+			for x := 0; x < benchSize; x++ {
+				sum += openSimplex.Get2D32(float32(x), float32(y))
+			}
+		}
+	}
+}
+
+func BenchmarkFBM2DBuilder32(b *testing.B) {
+	const benchSize = 100
+
+	// make a test generator seeded to 1
+	perlin := NewPerlinGenerator32Seed(1)
+	fbm := NewFBMGenerator2D32(&perlin, 4, 0.5, 2.0, 1.0)
+	builder := NewBuilder2D32(&fbm, benchSize, benchSize)
+	builder.Bounds = Builder2DBounds32{0.0, 0.0, benchSize, benchSize}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		builder.Build()
+	}
+}
+
+func BenchmarkScale2D(b *testing.B) {
+	var sum float64 = 0
+	const benchSize = 100
+
+	// make a test generator seeded to 1
+	perlin := NewPerlinGeneratorSeed(1)
+	scale := NewScale2D(&perlin, 0.5, 0.5, 0.0, 1.0)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for y := 0; y < benchSize; y++ {
+			for x := 0; x < benchSize; x++ {
+				sum += scale.Get2D(float64(x)*0.1, float64(y)*0.1)
+			}
+		}
+	}
+}
+
+func BenchmarkScale3D(b *testing.B) {
+	var sum float64 = 0
+	const benchSize = 100
+
+	// make a test generator seeded to 1
+	perlin := NewPerlinGeneratorSeed(1)
+	scale := NewScale3D(&perlin, 0.5, 0.5, 0.0, 1.0)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for y := 0; y < benchSize; y++ {
+			for x := 0; x < benchSize; x++ {
+				sum += scale.Get3D(float64(x)*0.1, float64(y)*0.1, 0.5)
+			}
+		}
+	}
+}
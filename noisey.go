@@ -3,22 +3,36 @@ Package noisey is a library that implements coherent noise algorithms.
 
 The selection is currently:
 
-	* 2D/3D Perlin noise (64bit)
+	* 2D/3D Perlin noise (64bit) with selectable interpolation quality
+	* 2D/3D improved "classic" Perlin noise matching Ken Perlin's reference (64bit)
 	* 2D/3D OpenSimplex noise (64bit)
+	* 2D/3D value noise with selectable interpolation quality (64bit)
+	* 2D/3D white noise (64bit)
+	* 2D/3D constant, checkerboard, cylinders and spheres primitives
 
 The sources above can be combined with different generators and modifiers
 like the following:
 
-	* FBMGenerator2D - fractal Brownian Motion
-	* Select2D - choose from source A or B depending on control source
-	* Scale2D - modify output by multiplying by a scale and adding a bias constant
+	* FBMGenerator2D/FBMGenerator3D - fractal Brownian Motion
+	* Select2D/Select3D - choose from source A or B depending on control source
+	* Scale2D/Scale3D - modify output by multiplying by a scale and adding a bias constant
+	* Abs, Invert, Clamp and Exponent - simple transformations of a single source
+	* Cache2D/Cache3D - remember the last value of a source used by several modules
+	* TileCache2D - keep tiles of a source's values for builders in an LRU cache
 
 
 Once the noise generators have been set up, a Builder2D object can be created
 to map a region of noise into a float64 array.
+Sources implementing NoiseyBatch2D or NoiseyBatch3D can also fill whole
+slices of values at once with GetBatch2D() and GetGrid2D().
+
+Float32 versions of the Perlin and OpenSimplex sources, the fBm generators and
+Builder2D are available for real-time clients working in float32.
 
 An interface called 'RandomSource' is also exported so that a client can implement
-a different random number generator and pass it to the noise generators.
+a different random number generator and pass it to the noise generators. The
+PCG32 and SplitMix64 implementations are portable and deterministic, unlike
+math/rand, so noise built with them is the same everywhere and forever.
 
 Sample programs can be found in the 'examples' directory.
 
@@ -68,6 +82,45 @@ type Vec3i struct {
 	X, Y, Z int
 }
 
+// NoiseQuality selects the interpolation used by sources that support it.
+// The numbering differs from libnoise, where fast, standard and best are
+// 0, 1 and 2: QualityAttenuated comes first so that the zero value keeps the
+// original PerlinGenerator algorithm, which shifts the others up by one.
+// NoiseJSON files use libnoise's numbering instead; see SourceJSON.Quality.
+type NoiseQuality int
+
+const (
+	// QualityAttenuated blends the lattice gradients with a radial attenuation
+	// instead of interpolating them. This is the original PerlinGenerator
+	// algorithm ported from noise-rs and the default for compatibility.
+	QualityAttenuated NoiseQuality = iota
+
+	// QualityFast linearly interpolates between the lattice gradients. It is
+	// the fastest but shows creases along the lattice lines.
+	QualityFast
+
+	// QualityStandard interpolates between the lattice gradients with a
+	// cubic s-curve, which is smooth in value but not in its derivative.
+	QualityStandard
+
+	// QualityBest interpolates between the lattice gradients with a quintic
+	// s-curve so that the second derivative is continuous as well.
+	QualityBest
+)
+
+// sCurve maps the fractional lattice coordinate v through the interpolation
+// curve of the quality level.
+func (q NoiseQuality) sCurve(v float64) float64 {
+	switch q {
+	case QualityFast:
+		return v
+	case QualityStandard:
+		return calcCubicSCurve(v)
+	default:
+		return calcQuinticSCurve(v)
+	}
+}
+
 func calcCubicSCurve(v float64) float64 {
 	return v * v * (3 - 2*v)
 }
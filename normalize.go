@@ -0,0 +1,240 @@
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+/*
+
+This module contains the normalization modules, which remap the values of a
+source from its range to a target range like -1..1 without having to tune a
+Scale2D by hand.
+
+The range of the source can be given directly, calculated from the parameters
+of an fBm generator or estimated by sampling the source:
+
+  fbm := noisey.NewFBMGenerator2D(&perlin, 5, 0.5, 2.0, 1.0)
+
+  // the fBm can at most reach 1 + 0.5 + 0.25 + 0.125 + 0.0625 = 1.9375
+  norm, err := noisey.NewNormalize2DFBM(&fbm, -1.0, 1.0)
+
+  // or sample it at 10000 random points
+  norm, err := noisey.NewNormalize2DSampled(&fbm, noisey.Builder2DBounds{-64.0, -64.0, 64.0, 64.0}, 10000, noisey.NewPCG32(1), -1.0, 1.0)
+
+The fBm bound assumes its source stays in -1..1 and is never exceeded, but
+real noise seldom gets close to it, so the output tends to use a bit less
+than the target range. Not every source stays in -1..1 though: the default
+attenuated PerlinGenerator reaches about 1.11 in 2D and 1.13 in 3D with a mean
+of about 0.056, and the other qualities of 3D Perlin overshoot by up to 0.1 as
+well. The rare peaks of an fBm over such a source can exceed the bound and get
+clamped; normalize with a sampled range, or give the range directly, if they
+matter. A sampled range fits more tightly but may be exceeded
+at points that weren't sampled. Either way the output is clamped to the
+target range. Sampling needs at least one sample with a finite value, or
+there would be no range to remap from, so anything else returns an error.
+
+In NoiseJSON these are the "normalize2d" and "normalize3d" generator types,
+which take one generator and remap it to Min..Max (-1..1 if both are 0).
+If Samples is 0 the generator must be an fBm and its bound is used;
+otherwise it's sampled that many times inside SampleBounds, which defaults to
+-64..64 on every axis.
+
+*/
+
+import (
+	"fmt"
+	"math"
+)
+
+// Normalize2D is a module that remaps the noise from Source from the range
+// InMin..InMax to OutMin..OutMax, clamping the result to OutMin..OutMax.
+type Normalize2D struct {
+	// the noise that the normalize module uses
+	Source NoiseyGet2D
+
+	// the range of the values from Source
+	InMin, InMax float64
+
+	// the range to map the values to
+	OutMin, OutMax float64
+}
+
+// NewNormalize2D creates a new normalize 2d module for a source whose values
+// are in inMin..inMax.
+func NewNormalize2D(src NoiseyGet2D, inMin, inMax, outMin, outMax float64) (norm Normalize2D) {
+	norm.Source = src
+	norm.InMin = inMin
+	norm.InMax = inMax
+	norm.OutMin = outMin
+	norm.OutMax = outMax
+	return
+}
+
+// NewNormalize2DFBM creates a new normalize 2d module for an fBm generator,
+// using the bound on its values calculated by FBMBound(). The generator may
+// be wrapped in a Cache2D; an error is returned if src isn't an fBm generator.
+func NewNormalize2DFBM(src NoiseyGet2D, outMin, outMax float64) (Normalize2D, error) {
+	bound, ok := fbmBound2D(src)
+	if !ok {
+		return Normalize2D{}, fmt.Errorf("The source to normalize isn't an fBm generator.")
+	}
+	return NewNormalize2D(src, -bound, bound, outMin, outMax), nil
+}
+
+// NewNormalize2DSampled creates a new normalize 2d module using the range of
+// the values of src at samples random points inside bounds, picked with rng.
+// An error is returned if samples isn't positive or no sampled value is finite.
+func NewNormalize2DSampled(src NoiseyGet2D, bounds Builder2DBounds, samples int, rng RandomSource, outMin, outMax float64) (Normalize2D, error) {
+	if samples <= 0 {
+		return Normalize2D{}, fmt.Errorf("The number of samples to normalize with must be positive, not %d.", samples)
+	}
+	stats := SampleStats2D(src, bounds, samples, rng, 0)
+	if stats.Count == 0 {
+		return Normalize2D{}, fmt.Errorf("None of the %d sampled values of the source to normalize are finite.", samples)
+	}
+	return NewNormalize2D(src, stats.Min, stats.Max, outMin, outMax), nil
+}
+
+// Get2D calculates the noise value remapped to OutMin..OutMax.
+func (norm *Normalize2D) Get2D(x float64, y float64) float64 {
+	return normalizeValue(norm.Source.Get2D(x, y), norm.InMin, norm.InMax, norm.OutMin, norm.OutMax)
+}
+
+// GetBatch2D fills out with the remapped noise at the coordinates in xs and ys.
+func (norm *Normalize2D) GetBatch2D(xs, ys []float64, out []float64) {
+	GetBatch2D(norm.Source, xs, ys, out)
+	norm.apply(out)
+}
+
+// GetGrid2D fills out with a grid of remapped noise values. See NoiseyBatch2D.
+func (norm *Normalize2D) GetGrid2D(minX, minY, dx, dy float64, width, height int, out []float64) {
+	GetGrid2D(norm.Source, minX, minY, dx, dy, width, height, out)
+	norm.apply(out[:width*height])
+}
+
+// apply remaps the values in place like Normalize2D.Get2D().
+func (norm *Normalize2D) apply(values []float64) {
+	for i, v := range values {
+		values[i] = normalizeValue(v, norm.InMin, norm.InMax, norm.OutMin, norm.OutMax)
+	}
+}
+
+// Normalize3D is a module that remaps the noise from Source from the range
+// InMin..InMax to OutMin..OutMax, clamping the result to OutMin..OutMax.
+type Normalize3D struct {
+	// the noise that the normalize module uses
+	Source NoiseyGet3D
+
+	// the range of the values from Source
+	InMin, InMax float64
+
+	// the range to map the values to
+	OutMin, OutMax float64
+}
+
+// NewNormalize3D creates a new normalize 3d module for a source whose values
+// are in inMin..inMax.
+func NewNormalize3D(src NoiseyGet3D, inMin, inMax, outMin, outMax float64) (norm Normalize3D) {
+	norm.Source = src
+	norm.InMin = inMin
+	norm.InMax = inMax
+	norm.OutMin = outMin
+	norm.OutMax = outMax
+	return
+}
+
+// NewNormalize3DFBM creates a new normalize 3d module for an fBm generator,
+// using the bound on its values calculated by FBMBound(). The generator may
+// be wrapped in a Cache3D; an error is returned if src isn't an fBm generator.
+func NewNormalize3DFBM(src NoiseyGet3D, outMin, outMax float64) (Normalize3D, error) {
+	bound, ok := fbmBound3D(src)
+	if !ok {
+		return Normalize3D{}, fmt.Errorf("The source to normalize isn't an fBm generator.")
+	}
+	return NewNormalize3D(src, -bound, bound, outMin, outMax), nil
+}
+
+// NewNormalize3DSampled creates a new normalize 3d module using the range of
+// the values of src at samples random points inside the box from min to max,
+// picked with rng. An error is returned if samples isn't positive or no
+// sampled value is finite.
+func NewNormalize3DSampled(src NoiseyGet3D, min, max Vec3f, samples int, rng RandomSource, outMin, outMax float64) (Normalize3D, error) {
+	if samples <= 0 {
+		return Normalize3D{}, fmt.Errorf("The number of samples to normalize with must be positive, not %d.", samples)
+	}
+	stats := SampleStats3D(src, min, max, samples, rng, 0)
+	if stats.Count == 0 {
+		return Normalize3D{}, fmt.Errorf("None of the %d sampled values of the source to normalize are finite.", samples)
+	}
+	return NewNormalize3D(src, stats.Min, stats.Max, outMin, outMax), nil
+}
+
+// Get3D calculates the noise value remapped to OutMin..OutMax.
+func (norm *Normalize3D) Get3D(x float64, y float64, z float64) float64 {
+	return normalizeValue(norm.Source.Get3D(x, y, z), norm.InMin, norm.InMax, norm.OutMin, norm.OutMax)
+}
+
+// GetBatch3D fills out with the remapped noise at the coordinates in xs, ys and zs.
+func (norm *Normalize3D) GetBatch3D(xs, ys, zs []float64, out []float64) {
+	GetBatch3D(norm.Source, xs, ys, zs, out)
+	norm.apply(out)
+}
+
+// GetGrid3D fills out with a grid of remapped noise values. See NoiseyBatch3D.
+func (norm *Normalize3D) GetGrid3D(minX, minY, minZ, dx, dy, dz float64, width, height, depth int, out []float64) {
+	GetGrid3D(norm.Source, minX, minY, minZ, dx, dy, dz, width, height, depth, out)
+	norm.apply(out[:width*height*depth])
+}
+
+// apply remaps the values in place like Normalize3D.Get3D().
+func (norm *Normalize3D) apply(values []float64) {
+	for i, v := range values {
+		values[i] = normalizeValue(v, norm.InMin, norm.InMax, norm.OutMin, norm.OutMax)
+	}
+}
+
+// normalizeValue maps v from inMin..inMax to outMin..outMax and clamps it.
+// If the input range is empty, the middle of the output range is returned.
+func normalizeValue(v, inMin, inMax, outMin, outMax float64) float64 {
+	if inMax == inMin {
+		return (outMin + outMax) * 0.5
+	}
+	v = lerp(outMin, outMax, (v-inMin)/(inMax-inMin))
+	return math.Max(math.Min(outMin, outMax), math.Min(math.Max(outMin, outMax), v))
+}
+
+// FBMBound returns the largest magnitude that an fBm generator with the given
+// octaves and persistence can reach if its source stays in -1..1. Sources like
+// the attenuated PerlinGenerator slightly exceed that; see the notes at the
+// top of this file.
+func FBMBound(octaves int, persistence float64) (bound float64) {
+	amplitude := 1.0
+	for o := 0; o < octaves; o++ {
+		bound += math.Abs(amplitude)
+		amplitude *= persistence
+	}
+	return
+}
+
+// fbmBound2D returns the FBMBound() of src if it's an fBm generator, looking
+// through caches.
+func fbmBound2D(src NoiseyGet2D) (float64, bool) {
+	switch g := src.(type) {
+	case *FBMGenerator2D:
+		return FBMBound(g.Octaves, g.Persistence), true
+	case *Cache2D:
+		return fbmBound2D(g.Source)
+	}
+	return 0.0, false
+}
+
+// fbmBound3D returns the FBMBound() of src if it's an fBm generator, looking
+// through caches.
+func fbmBound3D(src NoiseyGet3D) (float64, bool) {
+	switch g := src.(type) {
+	case *FBMGenerator3D:
+		return FBMBound(g.Octaves, g.Persistence), true
+	case *Cache3D:
+		return fbmBound3D(g.Source)
+	}
+	return 0.0, false
+}
@@ -0,0 +1,139 @@
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNormalize2D(t *testing.T) {
+	c := NewConstGenerator(0.5)
+	norm := NewNormalize2D(&c, 0.0, 2.0, -1.0, 1.0)
+	if v := norm.Get2D(1.0, 1.0); v != -0.5 {
+		t.Errorf("Normalize2D mapped 0.5 from 0..2 to %f", v)
+	}
+	c.Value = 5.0
+	if v := norm.Get2D(1.0, 1.0); v != 1.0 {
+		t.Errorf("Normalize2D didn't clamp 5.0 to 1.0: %f", v)
+	}
+
+	if b := FBMBound(3, 0.5); b != 1.75 {
+		t.Errorf("FBMBound(3, 0.5) = %f", b)
+	}
+
+	perlin := NewPerlinGeneratorSeed(1)
+	fbm := NewFBMGenerator2D(&perlin, 6, 0.7, 2.0, 1.0)
+	cached := NewCache2D(&fbm)
+	fbmNorm, err := NewNormalize2DFBM(&cached, -1.0, 1.0)
+	if err != nil {
+		t.Fatalf("NewNormalize2DFBM failed: %v", err)
+	}
+	if fbmNorm.InMax != FBMBound(6, 0.7) || fbmNorm.InMin != -fbmNorm.InMax {
+		t.Errorf("NewNormalize2DFBM used the range %f..%f", fbmNorm.InMin, fbmNorm.InMax)
+	}
+	if _, err = NewNormalize2DFBM(&perlin, -1.0, 1.0); err == nil {
+		t.Errorf("NewNormalize2DFBM accepted a perlin source")
+	}
+
+	bounds := Builder2DBounds{-8.0, -8.0, 8.0, 8.0}
+	sampled, err := NewNormalize2DSampled(&fbm, bounds, 5000, NewPCG32(1), 0.0, 1.0)
+	if err != nil {
+		t.Fatalf("NewNormalize2DSampled failed: %v", err)
+	}
+	stats := SampleStats2D(&sampled, bounds, 5000, NewPCG32(1), 0)
+	if stats.Min != 0.0 || stats.Max != 1.0 {
+		t.Errorf("NewNormalize2DSampled remapped the samples to %f..%f", stats.Min, stats.Max)
+	}
+}
+
+func TestNormalize3DSampled(t *testing.T) {
+	perlin := NewPerlinGeneratorSeed(1)
+	fbm := NewFBMGenerator3D(&perlin, 3, 0.5, 2.0, 1.0)
+	min, max := Vec3f{-8.0, -8.0, -8.0}, Vec3f{8.0, 8.0, 8.0}
+	sampled, err := NewNormalize3DSampled(&fbm, min, max, 5000, NewPCG32(1), 0.0, 1.0)
+	if err != nil {
+		t.Fatalf("NewNormalize3DSampled failed: %v", err)
+	}
+	stats := SampleStats3D(&sampled, min, max, 5000, NewPCG32(1), 0)
+	if stats.Min != 0.0 || stats.Max != 1.0 {
+		t.Errorf("NewNormalize3DSampled remapped the samples to %f..%f", stats.Min, stats.Max)
+	}
+}
+
+func TestNormalizeSampledErrors(t *testing.T) {
+	perlin := NewPerlinGeneratorSeed(1)
+	bounds := Builder2DBounds{-8.0, -8.0, 8.0, 8.0}
+	min, max := Vec3f{-8.0, -8.0, -8.0}, Vec3f{8.0, 8.0, 8.0}
+	for _, samples := range []int{0, -1} {
+		if _, err := NewNormalize2DSampled(&perlin, bounds, samples, NewPCG32(1), -1.0, 1.0); err == nil {
+			t.Errorf("NewNormalize2DSampled accepted %d samples", samples)
+		}
+		if _, err := NewNormalize3DSampled(&perlin, min, max, samples, NewPCG32(1), -1.0, 1.0); err == nil {
+			t.Errorf("NewNormalize3DSampled accepted %d samples", samples)
+		}
+	}
+
+	// a source without any finite values has no range to normalize from
+	c := NewConstGenerator(math.NaN())
+	if _, err := NewNormalize2DSampled(&c, bounds, 10, NewPCG32(1), -1.0, 1.0); err == nil {
+		t.Errorf("NewNormalize2DSampled accepted a source that is always NaN")
+	}
+	if _, err := NewNormalize3DSampled(&c, min, max, 10, NewPCG32(1), -1.0, 1.0); err == nil {
+		t.Errorf("NewNormalize3DSampled accepted a source that is always NaN")
+	}
+}
+
+func TestNoiseJSONNormalize(t *testing.T) {
+	json := []byte(`{
+		"Seeds": { "Default": 1 },
+		"Sources": {
+			"perlin": { "SourceType": "perlin", "Seed": "Default" }
+		},
+		"Generators": [
+			{ "Name": "fbm", "GeneratorType": "fBm2d", "Sources": ["perlin"], "Octaves": 4, "Persistence": 0.5, "Lacunarity": 2.0, "Frequency": 1.0 },
+			{ "Name": "bound", "GeneratorType": "normalize2d", "Generators": ["fbm"] },
+			{ "Name": "sampled", "GeneratorType": "normalize2d", "Generators": ["fbm"], "Min": 0.0, "Max": 255.0, "Samples": 1000, "SampleBounds": [0, 0, 4, 4] },
+			{ "Name": "fbm3", "GeneratorType": "fBm3d", "Sources": ["perlin"], "Octaves": 2, "Persistence": 0.5, "Lacunarity": 2.0, "Frequency": 1.0 },
+			{ "Name": "bound3", "GeneratorType": "normalize3d", "Generators": ["fbm3"] }
+		]
+	}`)
+	cfg, err := LoadNoiseJSON(json)
+	if err != nil {
+		t.Fatalf("Failed to load the configuration: %v", err)
+	}
+	if err = cfg.BuildSources(nil); err != nil {
+		t.Fatalf("Failed to build the sources: %v", err)
+	}
+	if err = cfg.BuildGenerators(); err != nil {
+		t.Fatalf("Failed to build the generators: %v", err)
+	}
+
+	bound := cfg.GetGenerator("bound").(*Normalize2D)
+	if bound.InMax != 1.875 || bound.OutMin != -1.0 || bound.OutMax != 1.0 {
+		t.Errorf("normalize2d built %+v", bound)
+	}
+	sampled := cfg.GetGenerator("sampled").(*Normalize2D)
+	if sampled.OutMax != 255.0 || sampled.InMin >= sampled.InMax || math.Abs(sampled.InMax) > 1.875 {
+		t.Errorf("normalize2d with Samples built %+v", sampled)
+	}
+	if bound3 := cfg.GetGenerator3D("bound3").(*Normalize3D); bound3.InMax != 1.5 {
+		t.Errorf("normalize3d built %+v", bound3)
+	}
+
+	bad := []byte(`{
+		"Seeds": { "Default": 1 },
+		"Sources": { "perlin": { "SourceType": "perlin", "Seed": "Default" } },
+		"Generators": [
+			{ "Name": "fbm", "GeneratorType": "fBm2d", "Sources": ["perlin"], "Octaves": 1 },
+			{ "Name": "inv", "GeneratorType": "invert2d", "Generators": ["fbm"] },
+			{ "Name": "norm", "GeneratorType": "normalize2d", "Generators": ["inv"] }
+		]
+	}`)
+	cfg, _ = LoadNoiseJSON(bad)
+	cfg.BuildSources(nil)
+	if err = cfg.BuildGenerators(); err == nil {
+		t.Errorf("normalize2d without Samples accepted a generator that isn't an fBm")
+	}
+}
@@ -61,28 +61,76 @@ var (
 
 // OpenSimplexGenerator stores the state information for generating opensimplex noise.
 type OpenSimplexGenerator struct {
-	Rng             RandomSource // random number generator interface
+	Rng             RandomSource // random number generator interface; nil unless built with NewOpenSimplexGenerator()
 	Permutations    []int        // the random permutation table
 	PermGradIndex3D []int
+
+	// FullHash makes the gradients get picked by hashing the full 64 bit lattice
+	// coordinates with HashSeed instead of looking them up in Permutations, so
+	// the noise doesn't repeat every 256 units. See large_world.go.
+	FullHash bool
+
+	// HashSeed is the seed mixed into the lattice hash when FullHash is set.
+	// The constructors derive it from the permutation table.
+	HashSeed uint64
 }
 
 // NewOpenSimplexGenerator creates a new state object for the open simplex noise generator
 func NewOpenSimplexGenerator(rng RandomSource) (osg OpenSimplexGenerator) {
 	osg.Rng = rng
-	osg.Permutations = rng.Perm(permTableSize)
+	osg.setPermutations(rng.Perm(permTableSize))
+	return
+}
+
+// NewOpenSimplexGeneratorSeed creates a new state object for the open simplex noise
+// generator whose permutation table is built from seed. This produces the same
+// generator as passing NewPCG32(seed) to NewOpenSimplexGenerator() without keeping
+// the RNG around.
+func NewOpenSimplexGeneratorSeed(seed int64) (osg OpenSimplexGenerator) {
+	osg.Reseed(seed)
+	return
+}
 
-	// construct the gradient index table
+// NewOpenSimplexGeneratorFromPermutation creates a new state object for the open
+// simplex noise generator using a copy of perm, which must be a permutation of
+// 0..255, as the permutation table.
+func NewOpenSimplexGeneratorFromPermutation(perm []int) (osg OpenSimplexGenerator, err error) {
+	err = checkPermutation(perm, permTableSize)
+	if err != nil {
+		return
+	}
+	p := make([]int, permTableSize)
+	copy(p, perm)
+	osg.setPermutations(p)
+	return
+}
+
+// Reseed rebuilds the permutation tables from seed, the same way
+// NewOpenSimplexGeneratorSeed() does, and drops the Rng.
+func (osg *OpenSimplexGenerator) Reseed(seed int64) {
+	osg.Rng = nil
+	osg.setPermutations(NewPCG32(seed).Perm(permTableSize))
+}
+
+// setPermutations stores the permutation table and constructs the gradient
+// index table and HashSeed from it.
+func (osg *OpenSimplexGenerator) setPermutations(perm []int) {
+	osg.Permutations = perm
+	osg.HashSeed = permutationHashSeed(perm)
 	osg.PermGradIndex3D = make([]int, permTableSize)
 	gradLengthDiv3 := len(gradients3D) / 3
 	for i := range osg.PermGradIndex3D {
 		osg.PermGradIndex3D[i] = (osg.Permutations[i] % gradLengthDiv3) * 3
 	}
-
-	return
 }
 
 func (osg *OpenSimplexGenerator) extrapolate2(xsb int, ysb int, dx float64, dy float64) float64 {
-	index := osg.Permutations[(osg.Permutations[xsb&0xFF]+ysb)&0xFF] & 0x0E
+	var index int
+	if osg.FullHash {
+		index = int(hashLattice2(osg.HashSeed, xsb, ysb) & 0x0E)
+	} else {
+		index = osg.Permutations[(osg.Permutations[xsb&0xFF]+ysb)&0xFF] & 0x0E
+	}
 	return float64(gradients2D[index])*dx + float64(gradients2D[index+1])*dy
 }
 
@@ -106,13 +154,43 @@ func (osg *OpenSimplexGenerator) Get2D(x float64, y float64) float64 {
 	xins := xs - float64(xsb)
 	yins := ys - float64(ysb)
 
-	// sum those together to get a value that determines which region we're in
-	inSum := xins + yins
-
 	// positions relative to origin point
 	dx0 := x - xb
 	dy0 := y - yb
 
+	return osg.getSuperCell2D(xsb, ysb, xins, yins, dx0, dy0)
+}
+
+// Get2DSplit calculates the noise at the 2D coordinate (ix+fx, iy+fy). The
+// large integer parts are kept out of the floating point math so that the
+// noise keeps its precision far away from the origin; see large_world.go.
+func (osg *OpenSimplexGenerator) Get2DSplit(ix, iy int64, fx, fy float64) float64 {
+	// normalize the fractions to 0..1
+	x0, x := splitLattice(ix, fx)
+	y0, y := splitLattice(iy, fy)
+
+	// place input coordinates onto grid; the stretch of the integer part is
+	// split into an integer and a small remainder
+	stretchWhole, stretchFrac := splitProduct(int64(x0+y0), stretchConstant2D)
+	stretchFrac += (x + y) * stretchConstant2D
+	xsb, xins := splitLattice(int64(x0)+stretchWhole, x+stretchFrac)
+	ysb, yins := splitLattice(int64(y0)+stretchWhole, y+stretchFrac)
+
+	// skew out to get the coordinates relative to the rhombus origin
+	squishWhole, squishFrac := splitProduct(int64(xsb+ysb), squishConstant2D)
+	dx0 := float64(int64(x0-xsb)-squishWhole) + x - squishFrac
+	dy0 := float64(int64(y0-ysb)-squishWhole) + y - squishFrac
+
+	return osg.getSuperCell2D(xsb, ysb, xins, yins, dx0, dy0)
+}
+
+// getSuperCell2D calculates the noise for a point inside the rhombus super-cell
+// with origin (xsb, ysb) given the point's grid coordinates relative to the origin
+// (xins, yins) and its position relative to the origin point (dx0, dy0).
+func (osg *OpenSimplexGenerator) getSuperCell2D(xsb, ysb int, xins, yins, dx0, dy0 float64) float64 {
+	// sum those together to get a value that determines which region we're in
+	inSum := xins + yins
+
 	// we'll be defining these inside the next block and using them afterwards
 	var dx_ext, dy_ext float64
 	var xsv_ext, ysv_ext int
@@ -201,9 +279,14 @@ func (osg *OpenSimplexGenerator) Get2D(x float64, y float64) float64 {
 }
 
 func (osg *OpenSimplexGenerator) extrapolate3(xsb int, ysb int, zsb int, dx float64, dy float64, dz float64) float64 {
-	px := osg.Permutations[xsb&0xFF]
-	py := osg.Permutations[(px+ysb)&0xFF]
-	index := osg.PermGradIndex3D[(py+zsb)&0xFF]
+	var index int
+	if osg.FullHash {
+		index = osg.PermGradIndex3D[hashLattice3(osg.HashSeed, xsb, ysb, zsb)&0xFF]
+	} else {
+		px := osg.Permutations[xsb&0xFF]
+		py := osg.Permutations[(px+ysb)&0xFF]
+		index = osg.PermGradIndex3D[(py+zsb)&0xFF]
+	}
 	return float64(gradients3D[index])*dx + float64(gradients3D[index+1])*dy + float64(gradients3D[index+2])*dz
 }
 
@@ -231,14 +314,48 @@ func (osg *OpenSimplexGenerator) Get3D(x float64, y float64, z float64) float64
 	yins := ys - float64(ysb)
 	zins := zs - float64(zsb)
 
-	// Sum those together to get a value that determines which region we're in.
-	var inSum float64 = xins + yins + zins
-
 	// Positions relative to origin point.
 	dx0 := x - xb
 	dy0 := y - yb
 	dz0 := z - zb
 
+	return osg.getSuperCell3D(xsb, ysb, zsb, xins, yins, zins, dx0, dy0, dz0)
+}
+
+// Get3DSplit calculates the noise at the 3D coordinate (ix+fx, iy+fy, iz+fz).
+// The large integer parts are kept out of the floating point math so that the
+// noise keeps its precision far away from the origin; see large_world.go.
+func (osg *OpenSimplexGenerator) Get3DSplit(ix, iy, iz int64, fx, fy, fz float64) float64 {
+	// normalize the fractions to 0..1
+	x0, x := splitLattice(ix, fx)
+	y0, y := splitLattice(iy, fy)
+	z0, z := splitLattice(iz, fz)
+
+	// Place input coordinates on simplectic honeycomb; the stretch of the integer
+	// part is split into an integer and a small remainder
+	stretchWhole, stretchFrac := splitProduct(int64(x0+y0+z0), stretchConstant3D)
+	stretchFrac += (x + y + z) * stretchConstant3D
+	xsb, xins := splitLattice(int64(x0)+stretchWhole, x+stretchFrac)
+	ysb, yins := splitLattice(int64(y0)+stretchWhole, y+stretchFrac)
+	zsb, zins := splitLattice(int64(z0)+stretchWhole, z+stretchFrac)
+
+	// Skew out to get the coordinates relative to the rhombohedron origin.
+	squishWhole, squishFrac := splitProduct(int64(xsb+ysb+zsb), squishConstant3D)
+	dx0 := float64(int64(x0-xsb)-squishWhole) + x - squishFrac
+	dy0 := float64(int64(y0-ysb)-squishWhole) + y - squishFrac
+	dz0 := float64(int64(z0-zsb)-squishWhole) + z - squishFrac
+
+	return osg.getSuperCell3D(xsb, ysb, zsb, xins, yins, zins, dx0, dy0, dz0)
+}
+
+// getSuperCell3D calculates the noise for a point inside the rhombohedron
+// super-cell with origin (xsb, ysb, zsb) given the point's honeycomb coordinates
+// relative to the origin (xins, yins, zins) and its position relative to the
+// origin point (dx0, dy0, dz0).
+func (osg *OpenSimplexGenerator) getSuperCell3D(xsb, ysb, zsb int, xins, yins, zins, dx0, dy0, dz0 float64) float64 {
+	// Sum those together to get a value that determines which region we're in.
+	var inSum float64 = xins + yins + zins
+
 	// We'll be defining these inside the next block and using them afterwards.
 	var dx_ext0, dy_ext0, dz_ext0 float64
 	var dx_ext1, dy_ext1, dz_ext1 float64
@@ -0,0 +1,40 @@
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+/*
+
+This module contains the support for periodic (tileable) noise sources, which
+is enabled by setting the Period field of PerlinGenerator.
+
+PerlinGenerator works on a square lattice, so it wraps the integer lattice
+coordinates at the period before picking the gradients. The noise looks exactly
+like the non-periodic noise inside of the period, and Get2D(x+period, y) ==
+Get2D(x, y) for coordinates where x+period can be represented exactly as a
+float64, e.g. on the grid of a Builder2D whose bounds span the period with a
+power of two width.
+
+OpenSimplexGenerator can't be made periodic this way: it works on a skewed
+lattice, and moving along an axis by an integer amount never lands on an
+equivalent lattice point, so there is no period along the axes to wrap at.
+NoiseJSON rejects a Period on "opensimplex" sources.
+
+FBMGenerator2D and FBMGenerator3D keep the noise tileable if Lacunarity is an
+integer: every octave samples the source at coordinates scaled by Frequency
+times a whole power of Lacunarity, so all octaves repeat every Period/Frequency
+units.
+
+*/
+
+// wrapLattice wraps the lattice coordinate v into 0..period-1 if period is positive.
+func wrapLattice(v int, period int) int {
+	if period <= 0 {
+		return v
+	}
+	v %= period
+	if v < 0 {
+		v += period
+	}
+	return v
+}
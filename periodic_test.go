@@ -0,0 +1,55 @@
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+import (
+	"testing"
+)
+
+func TestPeriodicSources(t *testing.T) {
+	perlin := NewPerlinGeneratorSeed(1)
+	perlin.Period = Vec3i{8, 4, 2}
+	fbm := NewFBMGenerator2D(&perlin, 4, 0.5, 2.0, 1.0)
+
+	for _, p := range []float64{0.0, 0.25, 1.5, 3.125, 7.875} {
+		x, y, z := p, p*0.5, p*0.25
+		if perlin.Get2D(x, y) != perlin.Get2D(x+8, y) || perlin.Get2D(x, y) != perlin.Get2D(x, y-4) {
+			t.Errorf("perlin Get2D(%v, %v) isn't periodic", x, y)
+		}
+		if perlin.Get3D(x, y, z) != perlin.Get3D(x-8, y+4, z+2) {
+			t.Errorf("perlin Get3D(%v, %v, %v) isn't periodic", x, y, z)
+		}
+		if fbm.Get2D(x, y) != fbm.Get2D(x+8, y+4) {
+			t.Errorf("fBm Get2D(%v, %v) isn't periodic", x, y)
+		}
+	}
+
+	// the wrapped noise should match the regular noise inside the period
+	plain := NewPerlinGeneratorSeed(1)
+	if perlin.Get2D(1.3, 2.7) != plain.Get2D(1.3, 2.7) {
+		t.Errorf("periodic perlin differs from the regular noise inside the period")
+	}
+}
+
+func TestNoiseJSONPeriodicOpenSimplex(t *testing.T) {
+	json := []byte(`{
+		"Seeds": { "Default": 1 },
+		"Sources": {
+			"simplex": { "SourceType": "opensimplex", "Seed": "Default", "Period": [8, 8] }
+		}
+	}`)
+	cfg, err := LoadNoiseJSON(json)
+	if err != nil {
+		t.Fatalf("Failed to load the JSON: %v", err)
+	}
+	if err = cfg.BuildSources(nil); err == nil {
+		t.Errorf("BuildSources accepted a periodic opensimplex source")
+	}
+
+	// a zero period doesn't wrap, so it's still accepted
+	cfg.Sources["simplex"] = SourceJSON{SourceType: "opensimplex", Seed: "Default", Period: []int{0, 0}}
+	if err = cfg.BuildSources(nil); err != nil {
+		t.Errorf("BuildSources rejected an opensimplex source with a zero period: %v", err)
+	}
+}
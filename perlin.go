@@ -29,53 +29,118 @@ const (
 
 // PerlinGenerator stores the state information for generating perlin noise.
 type PerlinGenerator struct {
-	Rng             RandomSource // random number generator interface
+	Rng             RandomSource // random number generator interface; nil unless built with NewPerlinGenerator()
 	Permutations    []int        // the random permutation table
 	RandomGradients []Vec4f      // the random gradient table
+	Quality         NoiseQuality // the interpolation used between lattice points
+
+	// FullHash makes the gradients get picked by hashing the full 64 bit lattice
+	// coordinates with HashSeed instead of looking them up in Permutations, so
+	// the noise doesn't repeat every 256 units. See large_world.go.
+	FullHash bool
+
+	// HashSeed is the seed mixed into the lattice hash when FullHash is set.
+	// The constructors derive it from the permutation table.
+	HashSeed uint64
+
+	// Period makes the noise tileable by wrapping the lattice coordinates on
+	// each axis with a positive period, so that Get2D(x+Period.X, y) equals
+	// Get2D(x, y). Axes with a period <= 0 don't wrap.
+	Period Vec3i
 }
 
 // NewPerlinGenerator creates a new state object for the #D perlin noise generator
 func NewPerlinGenerator(rng RandomSource) (pg PerlinGenerator) {
 	pg.Rng = rng
-	pg.Permutations = rng.Perm(tableSize)
-
-	pg.RandomGradients = make([]Vec4f, 32)
-	pg.RandomGradients[1] = Vec4f{0.0, 1.0, 1.0, -1.0}    //  [ zero,  one,   one,  -one],
-	pg.RandomGradients[2] = Vec4f{0.0, 1.0, -1.0, 1.0}    // [ zero,  one,  -one,   one],
-	pg.RandomGradients[3] = Vec4f{0.0, 1.0, -1.0, -1.0}   // [ zero,  one,  -one,  -one],
-	pg.RandomGradients[4] = Vec4f{0.0, -1.0, 1.0, 1.0}    // [ zero, -one,   one,   one],
-	pg.RandomGradients[5] = Vec4f{0.0, -1.0, 1.0, -1.0}   // [ zero, -one,   one,  -one],
-	pg.RandomGradients[6] = Vec4f{0.0, -1.0, -1.0, 1.0}   // [ zero, -one,  -one,   one],
-	pg.RandomGradients[7] = Vec4f{0.0, -1.0, -1.0, -1.0}  // [ zero, -one,  -one,  -one],
-	pg.RandomGradients[8] = Vec4f{1.0, 0.0, 1.0, 1.0}     // [ one,   zero,  one,   one],
-	pg.RandomGradients[9] = Vec4f{1.0, 0.0, 1.0, -1.0}    // [ one,   zero,  one,  -one],
-	pg.RandomGradients[10] = Vec4f{1.0, 0.0, -1.0, 1.0}   // [ one,   zero, -one,   one],
-	pg.RandomGradients[11] = Vec4f{1.0, 0.0, -1.0, -1.0}  // [ one,   zero, -one,  -one],
-	pg.RandomGradients[12] = Vec4f{-1.0, 0.0, 1.0, 1.0}   // [-one,   zero,  one,   one],
-	pg.RandomGradients[13] = Vec4f{-1.0, 0.0, 1.0, -1.0}  // [-one,   zero,  one,  -one],
-	pg.RandomGradients[14] = Vec4f{-1.0, 0.0, -1.0, 1.0}  // [-one,   zero, -one,   one],
-	pg.RandomGradients[15] = Vec4f{-1.0, 0.0, -1.0, -1.0} // [-one,   zero, -one,  -one],
-	pg.RandomGradients[16] = Vec4f{1.0, 1.0, 0.0, 1.0}    // [ one,   one,   zero,  one],
-	pg.RandomGradients[17] = Vec4f{1.0, 1.0, 0.0, -1.0}   // [ one,   one,   zero, -one],
-	pg.RandomGradients[18] = Vec4f{1.0, -1.0, 0.0, 1.0}   // [ one,  -one,   zero,  one],
-	pg.RandomGradients[19] = Vec4f{0.0, -1.0, 0.0, -1.0}  // [ one,  -one,   zero, -one],
-	pg.RandomGradients[20] = Vec4f{-1.0, 1.0, 0.0, 1.0}   // [-one,   one,   zero,  one],
-	pg.RandomGradients[21] = Vec4f{-1.0, 1.0, 0.0, -1.0}  // [-one,   one,   zero, -one],
-	pg.RandomGradients[22] = Vec4f{-1.0, -1.0, 0.0, 1.0}  // [-one,  -one,   zero,  one],
-	pg.RandomGradients[23] = Vec4f{-1.0, -1.0, 0.0, -1.0} // [-one,  -one,   zero, -one],
-	pg.RandomGradients[24] = Vec4f{1.0, 1.0, 1.0, 0.0}    // [ one,   one,   one,   zero],
-	pg.RandomGradients[25] = Vec4f{1.0, 1.0, -1.0, 0.0}   // [ one,   one,  -one,   zero],
-	pg.RandomGradients[26] = Vec4f{1.0, -1.0, 1.0, 0.0}   // [ one,  -one,   one,   zero],
-	pg.RandomGradients[27] = Vec4f{1.0, -1.0, -1.0, 0.0}  // [ one,  -one,  -one,   zero],
-	pg.RandomGradients[28] = Vec4f{-1.0, 1.0, 1.0, 0.0}   // [-one,   one,   one,   zero],
-	pg.RandomGradients[29] = Vec4f{-1.0, 1.0, -1.0, 0.0}  // [-one,   one,  -one,   zero],
-	pg.RandomGradients[30] = Vec4f{-1.0, -1.0, 1.0, 0.0}  // [-one,  -one,   one,   zero],
-	pg.RandomGradients[31] = Vec4f{-1.0, -1.0, -1.0, 0.0} // [-one,  -one,  -one,   zero],
+	pg.setPermutations(rng.Perm(tableSize))
+	pg.RandomGradients = newPerlinGradients()
+	return
+}
 
+// NewPerlinGeneratorSeed creates a new state object for the perlin noise generator
+// whose permutation table is built from seed. This produces the same generator as
+// passing NewPCG32(seed) to NewPerlinGenerator() without keeping the RNG around.
+func NewPerlinGeneratorSeed(seed int64) (pg PerlinGenerator) {
+	pg.Reseed(seed)
+	pg.RandomGradients = newPerlinGradients()
 	return
 }
 
+// NewPerlinGeneratorFromPermutation creates a new state object for the perlin noise
+// generator using a copy of perm, which must be a permutation of 0..255, as the
+// permutation table.
+func NewPerlinGeneratorFromPermutation(perm []int) (pg PerlinGenerator, err error) {
+	err = checkPermutation(perm, tableSize)
+	if err != nil {
+		return
+	}
+	p := make([]int, tableSize)
+	copy(p, perm)
+	pg.setPermutations(p)
+	pg.RandomGradients = newPerlinGradients()
+	return
+}
+
+// Reseed rebuilds the permutation table from seed, the same way
+// NewPerlinGeneratorSeed() does, and drops the Rng.
+func (pg *PerlinGenerator) Reseed(seed int64) {
+	pg.Rng = nil
+	pg.setPermutations(NewPCG32(seed).Perm(tableSize))
+}
+
+// setPermutations stores the permutation table and derives HashSeed from it.
+func (pg *PerlinGenerator) setPermutations(perm []int) {
+	pg.Permutations = perm
+	pg.HashSeed = permutationHashSeed(perm)
+}
+
+// newPerlinGradients returns the gradient table used by PerlinGenerator.
+func newPerlinGradients() []Vec4f {
+	gradients := make([]Vec4f, 32)
+	gradients[1] = Vec4f{0.0, 1.0, 1.0, -1.0}    //  [ zero,  one,   one,  -one],
+	gradients[2] = Vec4f{0.0, 1.0, -1.0, 1.0}    // [ zero,  one,  -one,   one],
+	gradients[3] = Vec4f{0.0, 1.0, -1.0, -1.0}   // [ zero,  one,  -one,  -one],
+	gradients[4] = Vec4f{0.0, -1.0, 1.0, 1.0}    // [ zero, -one,   one,   one],
+	gradients[5] = Vec4f{0.0, -1.0, 1.0, -1.0}   // [ zero, -one,   one,  -one],
+	gradients[6] = Vec4f{0.0, -1.0, -1.0, 1.0}   // [ zero, -one,  -one,   one],
+	gradients[7] = Vec4f{0.0, -1.0, -1.0, -1.0}  // [ zero, -one,  -one,  -one],
+	gradients[8] = Vec4f{1.0, 0.0, 1.0, 1.0}     // [ one,   zero,  one,   one],
+	gradients[9] = Vec4f{1.0, 0.0, 1.0, -1.0}    // [ one,   zero,  one,  -one],
+	gradients[10] = Vec4f{1.0, 0.0, -1.0, 1.0}   // [ one,   zero, -one,   one],
+	gradients[11] = Vec4f{1.0, 0.0, -1.0, -1.0}  // [ one,   zero, -one,  -one],
+	gradients[12] = Vec4f{-1.0, 0.0, 1.0, 1.0}   // [-one,   zero,  one,   one],
+	gradients[13] = Vec4f{-1.0, 0.0, 1.0, -1.0}  // [-one,   zero,  one,  -one],
+	gradients[14] = Vec4f{-1.0, 0.0, -1.0, 1.0}  // [-one,   zero, -one,   one],
+	gradients[15] = Vec4f{-1.0, 0.0, -1.0, -1.0} // [-one,   zero, -one,  -one],
+	gradients[16] = Vec4f{1.0, 1.0, 0.0, 1.0}    // [ one,   one,   zero,  one],
+	gradients[17] = Vec4f{1.0, 1.0, 0.0, -1.0}   // [ one,   one,   zero, -one],
+	gradients[18] = Vec4f{1.0, -1.0, 0.0, 1.0}   // [ one,  -one,   zero,  one],
+	gradients[19] = Vec4f{0.0, -1.0, 0.0, -1.0}  // [ one,  -one,   zero, -one],
+	gradients[20] = Vec4f{-1.0, 1.0, 0.0, 1.0}   // [-one,   one,   zero,  one],
+	gradients[21] = Vec4f{-1.0, 1.0, 0.0, -1.0}  // [-one,   one,   zero, -one],
+	gradients[22] = Vec4f{-1.0, -1.0, 0.0, 1.0}  // [-one,  -one,   zero,  one],
+	gradients[23] = Vec4f{-1.0, -1.0, 0.0, -1.0} // [-one,  -one,   zero, -one],
+	gradients[24] = Vec4f{1.0, 1.0, 1.0, 0.0}    // [ one,   one,   one,   zero],
+	gradients[25] = Vec4f{1.0, 1.0, -1.0, 0.0}   // [ one,   one,  -one,   zero],
+	gradients[26] = Vec4f{1.0, -1.0, 1.0, 0.0}   // [ one,  -one,   one,   zero],
+	gradients[27] = Vec4f{1.0, -1.0, -1.0, 0.0}  // [ one,  -one,  -one,   zero],
+	gradients[28] = Vec4f{-1.0, 1.0, 1.0, 0.0}   // [-one,   one,   one,   zero],
+	gradients[29] = Vec4f{-1.0, 1.0, -1.0, 0.0}  // [-one,   one,  -one,   zero],
+	gradients[30] = Vec4f{-1.0, -1.0, 1.0, 0.0}  // [-one,  -one,   one,   zero],
+	gradients[31] = Vec4f{-1.0, -1.0, -1.0, 0.0} // [-one,  -one,  -one,   zero],
+
+	return gradients
+}
+
 func (pg *PerlinGenerator) getGradient2(whole Vec2i) Vec2f {
+	whole.X = wrapLattice(whole.X, pg.Period.X)
+	whole.Y = wrapLattice(whole.Y, pg.Period.Y)
+
+	if pg.FullHash {
+		i := hashLattice2(pg.HashSeed, whole.X, whole.Y) % 32
+		return Vec2f{pg.RandomGradients[i].X, pg.RandomGradients[i].Y}
+	}
+
 	x := whole.X & 0xFF
 	xv := pg.Permutations[x]
 
@@ -87,6 +152,15 @@ func (pg *PerlinGenerator) getGradient2(whole Vec2i) Vec2f {
 }
 
 func (pg *PerlinGenerator) getGradient3(whole Vec3i) Vec3f {
+	whole.X = wrapLattice(whole.X, pg.Period.X)
+	whole.Y = wrapLattice(whole.Y, pg.Period.Y)
+	whole.Z = wrapLattice(whole.Z, pg.Period.Z)
+
+	if pg.FullHash {
+		i := hashLattice3(pg.HashSeed, whole.X, whole.Y, whole.Z) % 32
+		return Vec3f{pg.RandomGradients[i].X, pg.RandomGradients[i].Y, pg.RandomGradients[i].Z}
+	}
+
 	x := whole.X & 0xFF
 	xv := pg.Permutations[x]
 
@@ -110,6 +184,29 @@ func vec2fDot(a, b Vec2f) float64 {
 
 // Get3D calculates the perlin noise at a given 3D coordinate
 func (pg *PerlinGenerator) Get3D(x, y, z float64) float64 {
+	floored := Vec3f{math.Floor(x), math.Floor(y), math.Floor(z)}
+	whole0 := Vec3i{int(floored.X), int(floored.Y), int(floored.Z)}
+	frac0 := Vec3f{x - floored.X, y - floored.Y, z - floored.Z}
+	return pg.getLattice3D(whole0, frac0)
+}
+
+// Get3DSplit calculates the perlin noise at the 3D coordinate (ix+fx, iy+fy, iz+fz).
+// Splitting the coordinate into an integer and a fractional part keeps the full
+// precision of the fraction far away from the origin; see large_world.go.
+func (pg *PerlinGenerator) Get3DSplit(ix, iy, iz int64, fx, fy, fz float64) float64 {
+	x0, x := splitLattice(ix, fx)
+	y0, y := splitLattice(iy, fy)
+	z0, z := splitLattice(iz, fz)
+	return pg.getLattice3D(Vec3i{x0, y0, z0}, Vec3f{x, y, z})
+}
+
+// getLattice3D calculates the perlin noise at the offset frac0 inside of the
+// lattice cell whose lowest corner is whole0.
+func (pg *PerlinGenerator) getLattice3D(whole0 Vec3i, frac0 Vec3f) float64 {
+	if pg.Quality != QualityAttenuated {
+		return pg.getInterpolated3D(whole0, frac0)
+	}
+
 	gradient3 := func(whole Vec3i, frac Vec3f) float64 {
 		attn := 1.0 - vec3fDot(frac, frac)
 		if attn > 0.0 {
@@ -119,10 +216,7 @@ func (pg *PerlinGenerator) Get3D(x, y, z float64) float64 {
 		}
 	}
 
-	floored := Vec3f{math.Floor(x), math.Floor(y), math.Floor(z)}
-	whole0 := Vec3i{int(floored.X), int(floored.Y), int(floored.Z)}
 	whole1 := Vec3i{whole0.X + 1, whole0.Y + 1, whole0.Z + 1}
-	frac0 := Vec3f{x - floored.X, y - floored.Y, z - floored.Z}
 	frac1 := Vec3f{frac0.X - 1, frac0.Y - 1, frac0.Z - 1}
 
 	f000 := gradient3(Vec3i{whole0.X, whole0.Y, whole0.Z}, Vec3f{frac0.X, frac0.Y, frac0.Z})
@@ -140,6 +234,28 @@ func (pg *PerlinGenerator) Get3D(x, y, z float64) float64 {
 
 // Get2D calculates the perlin noise at a given 2D coordinate
 func (pg *PerlinGenerator) Get2D(x, y float64) float64 {
+	floored := Vec2f{math.Floor(x), math.Floor(y)}
+	whole0 := Vec2i{int(floored.X), int(floored.Y)}
+	frac0 := Vec2f{x - floored.X, y - floored.Y}
+	return pg.getLattice2D(whole0, frac0)
+}
+
+// Get2DSplit calculates the perlin noise at the 2D coordinate (ix+fx, iy+fy).
+// Splitting the coordinate into an integer and a fractional part keeps the full
+// precision of the fraction far away from the origin; see large_world.go.
+func (pg *PerlinGenerator) Get2DSplit(ix, iy int64, fx, fy float64) float64 {
+	x0, x := splitLattice(ix, fx)
+	y0, y := splitLattice(iy, fy)
+	return pg.getLattice2D(Vec2i{x0, y0}, Vec2f{x, y})
+}
+
+// getLattice2D calculates the perlin noise at the offset frac0 inside of the
+// lattice cell whose lowest corner is whole0.
+func (pg *PerlinGenerator) getLattice2D(whole0 Vec2i, frac0 Vec2f) float64 {
+	if pg.Quality != QualityAttenuated {
+		return pg.getInterpolated2D(whole0, frac0)
+	}
+
 	gradient2 := func(whole Vec2i, frac Vec2f) float64 {
 		attn := 1.0 - vec2fDot(frac, frac)
 		if attn > 0.0 {
@@ -149,10 +265,7 @@ func (pg *PerlinGenerator) Get2D(x, y float64) float64 {
 		}
 	}
 
-	floored := Vec2f{math.Floor(x), math.Floor(y)}
-	whole0 := Vec2i{int(floored.X), int(floored.Y)}
 	whole1 := Vec2i{whole0.X + 1, whole0.Y + 1}
-	frac0 := Vec2f{x - floored.X, y - floored.Y}
 	frac1 := Vec2f{frac0.X - 1, frac0.Y - 1}
 
 	f00 := gradient2(Vec2i{whole0.X, whole0.Y}, Vec2f{frac0.X, frac0.Y})
@@ -163,3 +276,44 @@ func (pg *PerlinGenerator) Get2D(x, y float64) float64 {
 	// Arbitrary values to shift and scale noise to -1..1
 	return (f00 + f10 + f01 + f11 + 0.053179) * 1.056165
 }
+
+// getInterpolated2D calculates the perlin noise inside a lattice cell by
+// interpolating the gradient values of the lattice corners using the s-curve
+// selected by Quality.
+func (pg *PerlinGenerator) getInterpolated2D(whole0 Vec2i, frac0 Vec2f) float64 {
+	whole1 := Vec2i{whole0.X + 1, whole0.Y + 1}
+	frac1 := Vec2f{frac0.X - 1, frac0.Y - 1}
+
+	f00 := vec2fDot(frac0, pg.getGradient2(whole0))
+	f10 := vec2fDot(Vec2f{frac1.X, frac0.Y}, pg.getGradient2(Vec2i{whole1.X, whole0.Y}))
+	f01 := vec2fDot(Vec2f{frac0.X, frac1.Y}, pg.getGradient2(Vec2i{whole0.X, whole1.Y}))
+	f11 := vec2fDot(frac1, pg.getGradient2(whole1))
+
+	sx := pg.Quality.sCurve(frac0.X)
+	sy := pg.Quality.sCurve(frac0.Y)
+	return lerp(lerp(f00, f10, sx), lerp(f01, f11, sx), sy)
+}
+
+// getInterpolated3D calculates the perlin noise inside a lattice cell by
+// interpolating the gradient values of the lattice corners using the s-curve
+// selected by Quality.
+func (pg *PerlinGenerator) getInterpolated3D(whole0 Vec3i, frac0 Vec3f) float64 {
+	whole1 := Vec3i{whole0.X + 1, whole0.Y + 1, whole0.Z + 1}
+	frac1 := Vec3f{frac0.X - 1, frac0.Y - 1, frac0.Z - 1}
+
+	f000 := vec3fDot(Vec3f{frac0.X, frac0.Y, frac0.Z}, pg.getGradient3(Vec3i{whole0.X, whole0.Y, whole0.Z}))
+	f100 := vec3fDot(Vec3f{frac1.X, frac0.Y, frac0.Z}, pg.getGradient3(Vec3i{whole1.X, whole0.Y, whole0.Z}))
+	f010 := vec3fDot(Vec3f{frac0.X, frac1.Y, frac0.Z}, pg.getGradient3(Vec3i{whole0.X, whole1.Y, whole0.Z}))
+	f110 := vec3fDot(Vec3f{frac1.X, frac1.Y, frac0.Z}, pg.getGradient3(Vec3i{whole1.X, whole1.Y, whole0.Z}))
+	f001 := vec3fDot(Vec3f{frac0.X, frac0.Y, frac1.Z}, pg.getGradient3(Vec3i{whole0.X, whole0.Y, whole1.Z}))
+	f101 := vec3fDot(Vec3f{frac1.X, frac0.Y, frac1.Z}, pg.getGradient3(Vec3i{whole1.X, whole0.Y, whole1.Z}))
+	f011 := vec3fDot(Vec3f{frac0.X, frac1.Y, frac1.Z}, pg.getGradient3(Vec3i{whole0.X, whole1.Y, whole1.Z}))
+	f111 := vec3fDot(Vec3f{frac1.X, frac1.Y, frac1.Z}, pg.getGradient3(Vec3i{whole1.X, whole1.Y, whole1.Z}))
+
+	sx := pg.Quality.sCurve(frac0.X)
+	sy := pg.Quality.sCurve(frac0.Y)
+	sz := pg.Quality.sCurve(frac0.Z)
+	y0 := lerp(lerp(f000, f100, sx), lerp(f010, f110, sx), sy)
+	y1 := lerp(lerp(f001, f101, sx), lerp(f011, f111, sx), sy)
+	return lerp(y0, y1, sz)
+}
@@ -0,0 +1,130 @@
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestQualitySCurves(t *testing.T) {
+	expected := map[NoiseQuality]float64{
+		QualityFast:     0.25,
+		QualityStandard: 0.15625,
+		QualityBest:     0.103515625,
+	}
+	for quality, at := range expected {
+		if v := quality.sCurve(0.25); v != at {
+			t.Errorf("The s-curve of quality %d maps 0.25 to %v; expected %v", quality, v, at)
+		}
+		if quality.sCurve(0.0) != 0.0 || quality.sCurve(0.5) != 0.5 || quality.sCurve(1.0) != 1.0 {
+			t.Errorf("The s-curve of quality %d doesn't go through 0, 0.5 and 1", quality)
+		}
+	}
+}
+
+func TestPerlinQualityInterpolation(t *testing.T) {
+	for _, quality := range []NoiseQuality{QualityFast, QualityStandard, QualityBest} {
+		perlin := NewPerlinGeneratorSeed(5)
+		perlin.Quality = quality
+
+		// gradient noise is 0 at the lattice points and, along the edge
+		// between two of them, interpolates their gradients with the s-curve
+		if v := perlin.Get2D(3.0, 5.0); v != 0.0 {
+			t.Errorf("Quality %d gives %v at a lattice point", quality, v)
+		}
+		g0, g1 := perlin.getGradient2(Vec2i{3, 5}), perlin.getGradient2(Vec2i{4, 5})
+		for _, f := range []float64{0.125, 0.25, 0.625} {
+			expected := lerp(f*g0.X, (f-1.0)*g1.X, quality.sCurve(f))
+			if v := perlin.Get2D(3.0+f, 5.0); math.Abs(v-expected) > 1e-12 {
+				t.Errorf("Quality %d gives %v at %v along the edge; expected %v", quality, v, f, expected)
+			}
+		}
+	}
+}
+
+func TestPerlinQualityRange(t *testing.T) {
+	rng := NewPCG32(7)
+	for _, quality := range []NoiseQuality{QualityAttenuated, QualityFast, QualityStandard, QualityBest} {
+		perlin := NewPerlinGeneratorSeed(5)
+		perlin.Quality = quality
+		for i := 0; i < 20000; i++ {
+			x, y, z := rng.Float64()*200.0-100.0, rng.Float64()*200.0-100.0, rng.Float64()*200.0-100.0
+			v2, v3 := perlin.Get2D(x, y), perlin.Get3D(x, y, z)
+			if v2 < -1.0 || v2 > 1.0 || v3 < -1.0 || v3 > 1.0 {
+				t.Fatalf("Quality %d leaves -1..1 at (%v, %v, %v): %v and %v", quality, x, y, z, v2, v3)
+			}
+		}
+	}
+}
+
+func TestNoiseJSONQuality(t *testing.T) {
+	// NoiseJSON uses libnoise's numbering, with -1 for the attenuated blend
+	tests := []struct {
+		json       string
+		perlin     NoiseQuality
+		value      NoiseQuality
+		valueError bool
+	}{
+		{``, QualityAttenuated, QualityStandard, false},
+		{`, "Quality": -1`, QualityAttenuated, 0, true},
+		{`, "Quality": 0`, QualityFast, QualityFast, false},
+		{`, "Quality": 1`, QualityStandard, QualityStandard, false},
+		{`, "Quality": 2`, QualityBest, QualityBest, false},
+		{`, "Quality": "$q"`, QualityBest, QualityBest, false},
+	}
+	for _, test := range tests {
+		for _, sourceType := range []string{"perlin", "value"} {
+			cfg, err := LoadNoiseJSON([]byte(`{
+				"Seeds": { "Default": 1 },
+				"Params": { "q": 2 },
+				"Sources": { "noise": { "SourceType": "` + sourceType + `", "Seed": "Default"` + test.json + ` } }
+			}`))
+			if err != nil {
+				t.Fatalf("Unable to load the %s source with %q: %v", sourceType, test.json, err)
+			}
+			err = cfg.BuildSources(nil)
+			if sourceType == "value" && test.valueError {
+				if err == nil {
+					t.Errorf("The value source accepted %q", test.json)
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatalf("Unable to build the %s source with %q: %v", sourceType, test.json, err)
+			}
+
+			var quality NoiseQuality
+			expected := test.perlin
+			if sourceType == "perlin" {
+				quality = cfg.builtSources["noise"].(*PerlinGenerator).Quality
+			} else {
+				quality = cfg.builtSources["noise"].(*ValueNoiseGenerator).Quality
+				expected = test.value
+			}
+			if quality != expected {
+				t.Errorf("The %s source with %q has quality %d; expected %d", sourceType, test.json, quality, expected)
+			}
+		}
+	}
+
+	// a set quality is kept when the source is written out, even if it's 0
+	zero := 0
+	out, err := json.Marshal(SourceJSON{SourceType: "perlin", Seed: "Default", Quality: &zero})
+	if err != nil || !strings.Contains(string(out), `"Quality":0`) {
+		t.Errorf("Quality 0 wasn't written: %s %v", out, err)
+	}
+
+	for _, bad := range []int{-2, 3} {
+		cfg := NewNoiseJSON()
+		cfg.Seeds["Default"] = 1
+		q := bad
+		cfg.Sources["perlin"] = SourceJSON{SourceType: "perlin", Seed: "Default", Quality: &q}
+		if err := cfg.BuildSources(nil); err == nil {
+			t.Errorf("Quality %d was accepted", bad)
+		}
+	}
+}
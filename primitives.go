@@ -0,0 +1,128 @@
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+/*
+
+This module contains simple analytic sources that don't use any random
+numbers. They are handy for testing generator graphs and as masks:
+
+  * ConstGenerator - returns the same value everywhere
+  * CheckerboardGenerator - alternates between -1 and 1 in unit cells
+  * CylindersGenerator - concentric cylinders around the Y axis (stripes in 2D)
+  * SpheresGenerator - concentric spheres around the origin (rings in 2D)
+
+The cylinders and spheres are 1.0 on the surfaces that are a whole number of
+units from the center and fall off linearly to -1.0 halfway between them.
+These sources don't need a Seed entry when used in a NoiseJSON file.
+
+The formulas come from libnoise: http://libnoise.sourceforge.net/docs/
+
+*/
+
+import (
+	"math"
+)
+
+// ConstGenerator returns Value for every coordinate.
+type ConstGenerator struct {
+	Value float64 // the value to return
+}
+
+// NewConstGenerator creates a new constant source.
+func NewConstGenerator(value float64) (cg ConstGenerator) {
+	cg.Value = value
+	return
+}
+
+// Get2D returns Value.
+func (cg *ConstGenerator) Get2D(x float64, y float64) float64 {
+	return cg.Value
+}
+
+// Get3D returns Value.
+func (cg *ConstGenerator) Get3D(x float64, y float64, z float64) float64 {
+	return cg.Value
+}
+
+// CheckerboardGenerator returns alternating -1 and 1 values in a checkerboard
+// pattern of unit cells, scaled by Frequency.
+type CheckerboardGenerator struct {
+	Frequency float64 // the number of cells per unit length
+}
+
+// NewCheckerboardGenerator creates a new checkerboard source.
+func NewCheckerboardGenerator(frequency float64) (cg CheckerboardGenerator) {
+	cg.Frequency = frequency
+	return
+}
+
+// Get2D calculates the checkerboard value at a given 2D coordinate
+func (cg *CheckerboardGenerator) Get2D(x float64, y float64) float64 {
+	return cg.Get3D(x, y, 0.0)
+}
+
+// Get3D calculates the checkerboard value at a given 3D coordinate
+func (cg *CheckerboardGenerator) Get3D(x float64, y float64, z float64) float64 {
+	ix := int64(math.Floor(x * cg.Frequency))
+	iy := int64(math.Floor(y * cg.Frequency))
+	iz := int64(math.Floor(z * cg.Frequency))
+	if (ix^iy^iz)&1 != 0 {
+		return -1.0
+	}
+	return 1.0
+}
+
+// CylindersGenerator returns values from concentric cylinders centered on the
+// Y axis. Get2D samples the XY plane at z = 0, where the cylinders become
+// stripes parallel to the Y axis, so Get2D(x, y) equals Get3D(x, y, 0).
+type CylindersGenerator struct {
+	Frequency float64 // the number of cylinders per unit length
+}
+
+// NewCylindersGenerator creates a new cylinders source.
+func NewCylindersGenerator(frequency float64) (cg CylindersGenerator) {
+	cg.Frequency = frequency
+	return
+}
+
+// Get2D calculates the value of the stripes at a given 2D coordinate
+func (cg *CylindersGenerator) Get2D(x float64, y float64) float64 {
+	return shellValue(math.Abs(x) * cg.Frequency)
+}
+
+// Get3D calculates the value of the cylinders at a given 3D coordinate
+func (cg *CylindersGenerator) Get3D(x float64, y float64, z float64) float64 {
+	return shellValue(math.Hypot(x, z) * cg.Frequency)
+}
+
+// SpheresGenerator returns values from concentric spheres centered on the origin.
+type SpheresGenerator struct {
+	Frequency float64 // the number of spheres per unit length
+}
+
+// NewSpheresGenerator creates a new spheres source.
+func NewSpheresGenerator(frequency float64) (sg SpheresGenerator) {
+	sg.Frequency = frequency
+	return
+}
+
+// Get2D calculates the value of the spheres at a given 2D coordinate
+func (sg *SpheresGenerator) Get2D(x float64, y float64) float64 {
+	return shellValue(math.Hypot(x, y) * sg.Frequency)
+}
+
+// Get3D calculates the value of the spheres at a given 3D coordinate
+func (sg *SpheresGenerator) Get3D(x float64, y float64, z float64) float64 {
+	return shellValue(math.Sqrt(x*x+y*y+z*z) * sg.Frequency)
+}
+
+// shellValue maps the distance from the center to 1.0 on whole numbers
+// falling off to -1.0 halfway between them.
+func shellValue(dist float64) float64 {
+	fromSmaller := dist - math.Floor(dist)
+	fromLarger := 1.0 - fromSmaller
+	nearest := math.Min(fromSmaller, fromLarger)
+	return 1.0 - (nearest * 4.0)
+}
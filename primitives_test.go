@@ -0,0 +1,78 @@
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+import (
+	"testing"
+)
+
+func TestPrimitiveSources(t *testing.T) {
+	c := NewConstGenerator(0.25)
+	if c.Get2D(3.0, -7.0) != 0.25 || c.Get3D(1.0, 2.0, 3.0) != 0.25 {
+		t.Errorf("ConstGenerator didn't return its value")
+	}
+
+	cb := NewCheckerboardGenerator(2.0)
+	if cb.Get2D(0.25, 0.25) != 1.0 || cb.Get2D(0.75, 0.25) != -1.0 || cb.Get2D(-0.25, 0.25) != -1.0 || cb.Get3D(0.75, 0.75, 0.25) != 1.0 {
+		t.Errorf("CheckerboardGenerator cells are wrong")
+	}
+
+	cy := NewCylindersGenerator(1.0)
+	if cy.Get2D(3.0, 4.5) != 1.0 || cy.Get2D(0.5, 0.0) != -1.0 || cy.Get3D(3.0, 100.0, 4.0) != 1.0 {
+		t.Errorf("CylindersGenerator shells are wrong")
+	}
+	for _, p := range []float64{-2.3, -0.5, 0.0, 0.25, 1.7} {
+		if cy.Get2D(p, p*3.0) != cy.Get3D(p, p*3.0, 0.0) {
+			t.Errorf("CylindersGenerator Get2D(%v, %v) doesn't match Get3D at z = 0", p, p*3.0)
+		}
+	}
+
+	sp := NewSpheresGenerator(0.5)
+	if sp.Get3D(2.0, 4.0, 4.0) != 1.0 || sp.Get3D(0.0, 1.0, 0.0) != -1.0 || sp.Get2D(6.0, 8.0) != 1.0 {
+		t.Errorf("SpheresGenerator shells are wrong")
+	}
+}
+
+func TestNoiseJSONPrimitiveSources(t *testing.T) {
+	json := []byte(`{
+		"Sources": {
+			"const": { "SourceType": "const", "Value": 0.5 },
+			"checker": { "SourceType": "checkerboard", "Frequency": 2.0 },
+			"stripes": { "SourceType": "cylinders" },
+			"balls": { "SourceType": "spheres" }
+		},
+		"Generators": [
+			{
+				"Name": "scaled",
+				"GeneratorType": "fBm2d",
+				"Sources": [ "checker" ],
+				"Octaves": 1,
+				"Frequency": 1.0
+			}
+		]
+	}`)
+
+	cfg, err := LoadNoiseJSON(json)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cfg.BuildSources(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cfg.BuildGenerators()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.builtSources["const"].Get2D(1.0, 2.0) != 0.5 {
+		t.Errorf("const source has the wrong value")
+	}
+	if cfg.builtSources["stripes"].Get2D(3.0, 4.5) != 1.0 {
+		t.Errorf("cylinders source didn't default to a frequency of 1")
+	}
+	if cfg.GetGenerator("scaled").Get2D(0.75, 0.25) != -1.0 {
+		t.Errorf("checkerboard source didn't use its frequency")
+	}
+}
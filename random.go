@@ -0,0 +1,172 @@
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+/*
+
+This module contains deterministic, portable implementations of RandomSource.
+The sequence of numbers produced by math/rand is not guaranteed to stay the
+same across Go versions, so noise that has to regenerate identically forever
+(e.g. saved worlds) should be built with one of these instead. Their output
+for a given seed, including the result of Perm(), is fixed and locked by the
+golden tests in random_test.go.
+
+Reference material:
+* PCG, A Family of Better Random Number Generators: http://www.pcg-random.org/
+* SplitMix64: http://xoroshiro.di.unimi.it/splitmix64.c
+
+*/
+
+import (
+	"fmt"
+)
+
+const (
+	pcg32Multiplier = 6364136223846793005
+
+	// pcg32DefaultStream is the stream selector used by NewPCG32; it's the
+	// one used in the pcg32 reference demo program.
+	pcg32DefaultStream = 54
+)
+
+// PCG32 is a RandomSource implementing the pcg32 (XSH RR 64/32) generator.
+type PCG32 struct {
+	state uint64
+	inc   uint64
+}
+
+// NewPCG32 creates a new PCG32 generator seeded with seed.
+func NewPCG32(seed int64) *PCG32 {
+	return NewPCG32Stream(seed, pcg32DefaultStream)
+}
+
+// NewPCG32Stream creates a new PCG32 generator seeded with seed that produces
+// the stream of numbers selected by stream. Different streams with the same seed
+// produce unrelated sequences.
+func NewPCG32Stream(seed int64, stream uint64) *PCG32 {
+	r := new(PCG32)
+	r.inc = (stream << 1) | 1
+	r.Uint32()
+	r.state += uint64(seed)
+	r.Uint32()
+	return r
+}
+
+// Uint32 returns the next random 32 bit value.
+func (r *PCG32) Uint32() uint32 {
+	old := r.state
+	r.state = old*pcg32Multiplier + r.inc
+	xorShifted := uint32(((old >> 18) ^ old) >> 27)
+	rot := uint32(old >> 59)
+	return (xorShifted >> rot) | (xorShifted << ((-rot) & 31))
+}
+
+// Float64 returns a random value in [0.0, 1.0) built from 53 random bits.
+func (r *PCG32) Float64() float64 {
+	a := uint64(r.Uint32() >> 5)
+	b := uint64(r.Uint32() >> 6)
+	return float64(a<<26|b) / (1 << 53)
+}
+
+// Intn returns an unbiased random value in [0, n). It panics if n is not
+// in the range 1..2^32.
+func (r *PCG32) Intn(n int) int {
+	if n <= 0 || uint64(n) > 1<<32 {
+		panic("noisey: invalid argument to PCG32.Intn")
+	}
+	if uint64(n) == 1<<32 {
+		return int(r.Uint32())
+	}
+
+	// reject the values that would make the modulo biased
+	bound := uint32(n)
+	threshold := -bound % bound
+	for {
+		v := r.Uint32()
+		if v >= threshold {
+			return int(v % bound)
+		}
+	}
+}
+
+// Perm returns a random permutation of the integers [0, n).
+func (r *PCG32) Perm(n int) []int {
+	return portablePerm(n, r.Intn)
+}
+
+// SplitMix64 is a RandomSource implementing the SplitMix64 generator.
+type SplitMix64 struct {
+	state uint64
+}
+
+// NewSplitMix64 creates a new SplitMix64 generator seeded with seed.
+func NewSplitMix64(seed int64) *SplitMix64 {
+	r := new(SplitMix64)
+	r.state = uint64(seed)
+	return r
+}
+
+// Uint64 returns the next random 64 bit value.
+func (r *SplitMix64) Uint64() uint64 {
+	v := mixHash64(r.state)
+	r.state += 0x9e3779b97f4a7c15
+	return v
+}
+
+// Float64 returns a random value in [0.0, 1.0) built from 53 random bits.
+func (r *SplitMix64) Float64() float64 {
+	return float64(r.Uint64()>>11) / (1 << 53)
+}
+
+// Intn returns an unbiased random value in [0, n). It panics if n <= 0.
+func (r *SplitMix64) Intn(n int) int {
+	if n <= 0 {
+		panic("noisey: invalid argument to SplitMix64.Intn")
+	}
+
+	// reject the values that would make the modulo biased
+	bound := uint64(n)
+	threshold := -bound % bound
+	for {
+		v := r.Uint64()
+		if v >= threshold {
+			return int(v % bound)
+		}
+	}
+}
+
+// Perm returns a random permutation of the integers [0, n).
+func (r *SplitMix64) Perm(n int) []int {
+	return portablePerm(n, r.Intn)
+}
+
+// portablePerm shuffles the integers [0, n) with a Fisher-Yates shuffle
+// that draws from intn. The algorithm is part of the output contract of the
+// RandomSource implementations in this module and must not change.
+func portablePerm(n int, intn func(int) int) []int {
+	m := make([]int, n)
+	for i := range m {
+		m[i] = i
+	}
+	for i := n - 1; i > 0; i-- {
+		j := intn(i + 1)
+		m[i], m[j] = m[j], m[i]
+	}
+	return m
+}
+
+// checkPermutation returns an error if perm isn't a permutation of [0, size).
+func checkPermutation(perm []int, size int) error {
+	if len(perm) != size {
+		return fmt.Errorf("permutation table has %d entries but %d are required", len(perm), size)
+	}
+	seen := make([]bool, size)
+	for i, v := range perm {
+		if v < 0 || v >= size || seen[v] {
+			return fmt.Errorf("permutation table entry %d (%d) is out of range or repeated", i, v)
+		}
+		seen[v] = true
+	}
+	return nil
+}
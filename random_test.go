@@ -0,0 +1,152 @@
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+import (
+	"reflect"
+	"testing"
+)
+
+// The values in these tests are golden: if any of them change, noise built
+// from the portable RandomSource implementations changes too and saved worlds
+// will no longer regenerate identically.
+
+func TestPCG32Reference(t *testing.T) {
+	// output of the pcg32 reference demo program for pcg32_srandom(42, 54)
+	expected := []uint32{0xa15c02b7, 0x7b47f409, 0xba1d3330, 0x83d2f293, 0xbfa4784b, 0xcbed606e}
+	r := NewPCG32(42)
+	for i, e := range expected {
+		if v := r.Uint32(); v != e {
+			t.Errorf("PCG32 output %d = 0x%08x; expected 0x%08x", i, v, e)
+		}
+	}
+}
+
+func TestSplitMix64Reference(t *testing.T) {
+	// output of the splitmix64 reference code seeded with 0
+	expected := []uint64{0xe220a8397b1dcdaf, 0x6e789e6aa1b965f4}
+	r := NewSplitMix64(0)
+	for i, e := range expected {
+		if v := r.Uint64(); v != e {
+			t.Errorf("SplitMix64 output %d = 0x%016x; expected 0x%016x", i, v, e)
+		}
+	}
+}
+
+func TestPCG32Golden(t *testing.T) {
+	checkGoldenSource(t, "PCG32", func(s int64) RandomSource { return NewPCG32(s) }, []goldenPerm{
+		{1, []int{15, 13, 1, 8, 0, 2, 5, 4, 12, 6, 11, 14, 7, 3, 10, 9},
+			[]int{51, 115, 222, 134, 234, 119, 56, 223, 55, 71, 126, 173, 249, 2, 60, 192}, 14722522729516429878,
+			[2]float64{0.6071146506640145, 0.8070064150429078}},
+		{42, []int{9, 10, 1, 11, 8, 13, 0, 2, 3, 14, 4, 15, 5, 6, 12, 7},
+			[]int{221, 53, 127, 60, 17, 68, 1, 97, 37, 36, 202, 8, 147, 213, 23, 254}, 2699067356786595994,
+			[2]float64{0.6303102186438938, 0.7270080560068604}},
+	})
+}
+
+func TestSplitMix64Golden(t *testing.T) {
+	checkGoldenSource(t, "SplitMix64", func(s int64) RandomSource { return NewSplitMix64(s) }, []goldenPerm{
+		{1, []int{2, 11, 10, 6, 7, 13, 14, 0, 12, 5, 15, 9, 3, 8, 4, 1},
+			[]int{86, 84, 62, 52, 122, 157, 182, 140, 247, 197, 187, 40, 10, 127, 164, 99}, 14084686829034422556,
+			[2]float64{0.5665615751722809, 0.7457817572627011}},
+		{42, []int{12, 13, 4, 2, 14, 6, 7, 8, 11, 15, 9, 10, 3, 0, 1, 5},
+			[]int{203, 217, 124, 199, 53, 101, 223, 240, 163, 7, 212, 88, 47, 164, 9, 169}, 18067010524215768724,
+			[2]float64{0.7415648787718233, 0.1599103928769201}},
+	})
+}
+
+func TestNoiseJSONDefaultSource(t *testing.T) {
+	// BuildSources(nil) must keep building sources from PCG32
+	cfg, err := LoadNoiseJSON([]byte(`{
+		"Seeds": { "Default": 1 },
+		"Sources": {
+			"perlin": { "SourceType": "perlin", "Seed": "Default" },
+			"os": { "SourceType": "opensimplex", "Seed": "Default" }
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cfg.BuildSources(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v := cfg.builtSources["perlin"].Get2D(1.3, 4.7); v != -0.05561870506500004 {
+		t.Errorf("default perlin source returned %v", v)
+	}
+	if v := cfg.builtSources["os"].Get2D(1.3, 4.7); v != -0.190338804346151 {
+		t.Errorf("default opensimplex source returned %v", v)
+	}
+}
+
+// goldenPerm holds the locked output of a RandomSource for a seed.
+type goldenPerm struct {
+	seed     int64
+	perm16   []int      // Perm(16)
+	perm256  []int      // the first 16 values of Perm(256)
+	checksum uint64     // a checksum of all of Perm(256)
+	floats   [2]float64 // the first two Float64() values
+}
+
+func checkGoldenSource(t *testing.T, name string, build RandomSeedBuilder, golden []goldenPerm) {
+	for _, g := range golden {
+		if p := build(g.seed).Perm(16); !reflect.DeepEqual(p, g.perm16) {
+			t.Errorf("%s(%d).Perm(16) = %v; expected %v", name, g.seed, p, g.perm16)
+		}
+
+		p := build(g.seed).Perm(256)
+		if !reflect.DeepEqual(p[:16], g.perm256) {
+			t.Errorf("%s(%d).Perm(256) starts with %v; expected %v", name, g.seed, p[:16], g.perm256)
+		}
+		var checksum uint64
+		for _, v := range p {
+			checksum = checksum*31 + uint64(v)
+		}
+		if checksum != g.checksum {
+			t.Errorf("%s(%d).Perm(256) checksum = %d; expected %d", name, g.seed, checksum, g.checksum)
+		}
+
+		r := build(g.seed)
+		for i, e := range g.floats {
+			if v := r.Float64(); v != e {
+				t.Errorf("%s(%d) Float64 %d = %v; expected %v", name, g.seed, i, v, e)
+			}
+		}
+	}
+}
+
+func TestSeedConstructors(t *testing.T) {
+	const seed = 5
+
+	pg := NewPerlinGenerator(NewPCG32(seed))
+	pgSeed := NewPerlinGeneratorSeed(seed)
+	if !reflect.DeepEqual(pg.Permutations, pgSeed.Permutations) || pgSeed.Rng != nil {
+		t.Errorf("NewPerlinGeneratorSeed doesn't match NewPerlinGenerator with PCG32")
+	}
+	pgSeed.Reseed(seed + 1)
+	if reflect.DeepEqual(pg.Permutations, pgSeed.Permutations) {
+		t.Errorf("PerlinGenerator.Reseed didn't change the permutation table")
+	}
+
+	osg := NewOpenSimplexGenerator(NewPCG32(seed))
+	osgSeed := NewOpenSimplexGeneratorSeed(seed)
+	if !reflect.DeepEqual(osg.Permutations, osgSeed.Permutations) || !reflect.DeepEqual(osg.PermGradIndex3D, osgSeed.PermGradIndex3D) {
+		t.Errorf("NewOpenSimplexGeneratorSeed doesn't match NewOpenSimplexGenerator with PCG32")
+	}
+
+	_, err := NewPerlinGeneratorFromPermutation(PerlinReferencePermutation)
+	if err != nil {
+		t.Errorf("NewPerlinGeneratorFromPermutation rejected the reference table: %v", err)
+	}
+	_, err = NewOpenSimplexGeneratorFromPermutation(PerlinReferencePermutation[:255])
+	if err == nil {
+		t.Errorf("NewOpenSimplexGeneratorFromPermutation accepted a short table")
+	}
+	bad := make([]int, tableSize)
+	_, err = NewPerlinGeneratorFromPermutation(bad)
+	if err == nil {
+		t.Errorf("NewPerlinGeneratorFromPermutation accepted a table of repeated values")
+	}
+}
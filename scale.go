@@ -43,3 +43,42 @@ func (scales *Scale2D) Get2D(x float64, y float64) (v float64) {
   v = math.Min(scales.Max, v)
   return v
 }
+
+// Scale3D is a module that uses gets the noise from Source, scales
+// it and then adds a bias.
+type Scale3D struct {
+  // the noise that the select module uses
+  Source  NoiseyGet3D
+
+  // what to scale the noise value from Source by
+  Scale float64
+
+  // the const value to add to the scaled noise value
+  Bias float64
+
+  // the minimum value to return
+  Min float64
+
+  // the maximum value to return
+  Max float64
+}
+
+// NewScale3D creates a new scale 3d module.
+func NewScale3D(src NoiseyGet3D, scale float64, bias float64, min float64, max float64) (scales Scale3D) {
+  scales.Source = src
+  scales.Scale = scale
+  scales.Bias = bias
+  scales.Min = min
+  scales.Max = max
+  return
+}
+
+// Get3D calculates the noise value scaling it by Scale and adding Bias
+func (scales *Scale3D) Get3D(x float64, y float64, z float64) (v float64) {
+  v = scales.Source.Get3D(x, y, z)
+  v *= scales.Scale
+  v += scales.Bias
+  v = math.Max(scales.Min, v)
+  v = math.Min(scales.Max, v)
+  return v
+}
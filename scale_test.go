@@ -0,0 +1,73 @@
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+import (
+	"math"
+	"testing"
+)
+
+func TestScale3D(t *testing.T) {
+	perlin := NewPerlinGeneratorSeed(1)
+	scale2 := NewScale2D(&perlin, 2.0, 0.25, -0.5, 0.75)
+	scale3 := NewScale3D(&perlin, 2.0, 0.25, -0.5, 0.75)
+
+	for i := 0; i < 100; i++ {
+		x, y, z := float64(i)*0.37, float64(i)*-0.53, float64(i)*0.11
+		expected := math.Min(0.75, math.Max(-0.5, perlin.Get3D(x, y, z)*2.0+0.25))
+		if v := scale3.Get3D(x, y, z); v != expected {
+			t.Errorf("Scale3D.Get3D(%v, %v, %v) = %v; expected %v", x, y, z, v, expected)
+		}
+	}
+
+	// both modules have the same semantics for the same input values
+	for _, v := range []float64{-1.0, -0.3, 0.0, 0.2, 0.9} {
+		c := NewConstGenerator(v)
+		scale2.Source = &c
+		scale3.Source = &c
+		if scale3.Get3D(1, 2, 3) != scale2.Get2D(1, 2) {
+			t.Errorf("Scale3D and Scale2D differ for the value %v", v)
+		}
+	}
+}
+
+func TestNoiseJSONScale3D(t *testing.T) {
+	json := []byte(`{
+		"Seeds": { "Default": 1 },
+		"Sources": {
+			"perlin": { "SourceType": "perlin", "Seed": "Default" }
+		},
+		"Generators": [
+			{ "Name": "base", "GeneratorType": "fBm3d", "Sources": [ "perlin" ], "Octaves": 3, "Persistence": 0.5, "Lacunarity": 2.0, "Frequency": 1.0 },
+			{ "Name": "scaled", "GeneratorType": "scale3d", "Generators": [ "base" ], "Scale": 0.5, "Bias": 0.5, "Min": 0.0, "Max": 1.0 }
+		]
+	}`)
+
+	cfg, err := LoadNoiseJSON(json)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cfg.BuildSources(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cfg.BuildGenerators()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	perlin := NewPerlinGeneratorSeed(1)
+	fbm := NewFBMGenerator3D(&perlin, 3, 0.5, 2.0, 1.0)
+	scale := NewScale3D(&fbm, 0.5, 0.5, 0.0, 1.0)
+	scaled := cfg.GetGenerator3D("scaled")
+	if scaled == nil {
+		t.Fatal("the scale3d generator wasn't built")
+	}
+	if v := scaled.Get3D(0.3, 1.7, -2.2); v != scale.Get3D(0.3, 1.7, -2.2) {
+		t.Errorf("the scale3d generator returned %v; expected %v", v, scale.Get3D(0.3, 1.7, -2.2))
+	}
+	if cfg.GetGenerator("scaled") != nil {
+		t.Errorf("the scale3d generator was returned as a 2D generator")
+	}
+}
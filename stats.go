@@ -0,0 +1,192 @@
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+/*
+
+This module calculates statistics of noise values so that the output of a
+generator can be checked empirically, like whether a source really stays in
+-1..1 or what Bias a Scale2D needs to center it:
+
+  builder.Build()
+  stats := builder.Stats(20)
+  fmt.Printf("%f..%f mean %f stddev %f\n", stats.Min, stats.Max, stats.Mean, stats.StdDev())
+  fmt.Printf("median %f, 99th percentile %f\n", stats.Percentile(50.0), stats.Percentile(99.0))
+
+A generator can also be sampled directly at random points inside some bounds,
+which avoids sampling only the points of a regular grid:
+
+  stats := noisey.SampleStats2D(&perlin, bounds, 100000, noisey.NewPCG32(1), 20)
+
+SampleStats3D does the same for 3D sources inside a box. If the number of
+samples is 0 or less nothing gets sampled and Count is 0.
+
+Values that aren't numbers are counted in NaNs and infinite values in Infs,
+and both are left out of everything else.
+
+*/
+
+import (
+	"math"
+	"sort"
+)
+
+// Stats holds the statistics of a set of noise values.
+type Stats struct {
+	// the number of finite values, of values that aren't numbers and of
+	// infinite values
+	Count int
+	NaNs  int
+	Infs  int
+
+	// the lowest and the highest values; both are 0 if Count is 0
+	Min float64
+	Max float64
+
+	// the mean and the (population) variance of the values
+	Mean     float64
+	Variance float64
+
+	// Histogram counts the values in equally wide bins covering Min..Max;
+	// the last bin includes Max.
+	Histogram []int
+
+	// the values in ascending order, used for percentiles and histograms
+	sorted []float64
+}
+
+// NewStats calculates the statistics of values, counting them in a histogram
+// with the given number of bins. values isn't modified.
+func NewStats(values []float64, bins int) (s Stats) {
+	s.sorted = make([]float64, 0, len(values))
+	for _, v := range values {
+		if math.IsNaN(v) {
+			s.NaNs++
+			continue
+		}
+		if math.IsInf(v, 0) {
+			s.Infs++
+			continue
+		}
+		s.sorted = append(s.sorted, v)
+	}
+	sort.Float64s(s.sorted)
+
+	s.Count = len(s.sorted)
+	if s.Count > 0 {
+		s.Min = s.sorted[0]
+		s.Max = s.sorted[s.Count-1]
+	}
+
+	// Welford's method keeps the variance accurate for large counts
+	var m2 float64
+	for i, v := range s.sorted {
+		delta := v - s.Mean
+		s.Mean += delta / float64(i+1)
+		m2 += delta * (v - s.Mean)
+	}
+	if s.Count > 0 {
+		s.Variance = m2 / float64(s.Count)
+	}
+
+	s.Histogram = s.HistogramRange(s.Min, s.Max, bins)
+	return
+}
+
+// StdDev returns the standard deviation of the values.
+func (s *Stats) StdDev() float64 {
+	return math.Sqrt(s.Variance)
+}
+
+// Percentile returns the value below which p percent of the values fall,
+// interpolating linearly between the closest values. p is clamped to 0..100
+// and 0 is returned if there are no values.
+func (s *Stats) Percentile(p float64) float64 {
+	if s.Count == 0 {
+		return 0.0
+	}
+	p = math.Max(0.0, math.Min(100.0, p))
+	rank := p / 100.0 * float64(s.Count-1)
+	i := int(rank)
+	if i >= s.Count-1 {
+		return s.sorted[s.Count-1]
+	}
+	return lerp(s.sorted[i], s.sorted[i+1], rank-float64(i))
+}
+
+// HistogramRange counts the values in bins equally wide bins covering
+// min..max, like comparing sources on -1..1. Values outside of the range
+// aren't counted; the last bin includes max.
+func (s *Stats) HistogramRange(min float64, max float64, bins int) []int {
+	if bins < 1 {
+		return nil
+	}
+	histogram := make([]int, bins)
+	width := (max - min) / float64(bins)
+	for _, v := range s.sorted {
+		if v < min || v > max {
+			continue
+		}
+		bin := bins - 1
+		if width > 0.0 {
+			// clamp the bin, which also catches the NaN that a range too
+			// large for a float64 gives
+			pos := (v - min) / width
+			bin = 0
+			if pos >= float64(bins-1) {
+				bin = bins - 1
+			} else if pos > 0.0 {
+				bin = int(pos)
+			}
+		}
+		histogram[bin]++
+	}
+	return histogram
+}
+
+// Stats calculates the statistics of the Values built with Build(), counting
+// them in a histogram with the given number of bins.
+func (b *Builder2D) Stats(bins int) Stats {
+	return NewStats(b.Values, bins)
+}
+
+// SampleStats2D samples src at the given number of random points inside
+// bounds, picked with rng, and calculates the statistics of the values.
+func SampleStats2D(src NoiseyGet2D, bounds Builder2DBounds, samples int, rng RandomSource, bins int) Stats {
+	if samples <= 0 {
+		return NewStats(nil, bins)
+	}
+
+	xs := make([]float64, samples)
+	ys := make([]float64, samples)
+	for i := range xs {
+		xs[i] = lerp(bounds.MinX, bounds.MaxX, rng.Float64())
+		ys[i] = lerp(bounds.MinY, bounds.MaxY, rng.Float64())
+	}
+
+	values := make([]float64, samples)
+	GetBatch2D(src, xs, ys, values)
+	return NewStats(values, bins)
+}
+
+// SampleStats3D samples src at the given number of random points inside the
+// box from min to max, picked with rng, and calculates the statistics of the values.
+func SampleStats3D(src NoiseyGet3D, min, max Vec3f, samples int, rng RandomSource, bins int) Stats {
+	if samples <= 0 {
+		return NewStats(nil, bins)
+	}
+
+	xs := make([]float64, samples)
+	ys := make([]float64, samples)
+	zs := make([]float64, samples)
+	for i := range xs {
+		xs[i] = lerp(min.X, max.X, rng.Float64())
+		ys[i] = lerp(min.Y, max.Y, rng.Float64())
+		zs[i] = lerp(min.Z, max.Z, rng.Float64())
+	}
+
+	values := make([]float64, samples)
+	GetBatch3D(src, xs, ys, zs, values)
+	return NewStats(values, bins)
+}
@@ -0,0 +1,105 @@
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+import (
+	"math"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	s := NewStats([]float64{4.0, 1.0, math.NaN(), 3.0, 2.0, 5.0}, 2)
+	if s.Count != 5 || s.NaNs != 1 {
+		t.Errorf("Stats counted %d values and %d NaNs", s.Count, s.NaNs)
+	}
+	if s.Min != 1.0 || s.Max != 5.0 || s.Mean != 3.0 || s.Variance != 2.0 {
+		t.Errorf("Stats calculated min %f, max %f, mean %f, variance %f", s.Min, s.Max, s.Mean, s.Variance)
+	}
+	if len(s.Histogram) != 2 || s.Histogram[0] != 2 || s.Histogram[1] != 3 {
+		t.Errorf("Stats calculated the histogram %v", s.Histogram)
+	}
+	if s.Percentile(0.0) != 1.0 || s.Percentile(50.0) != 3.0 || s.Percentile(100.0) != 5.0 || s.Percentile(62.5) != 3.5 {
+		t.Errorf("Stats calculated the wrong percentiles")
+	}
+	if h := s.HistogramRange(0.0, 4.0, 4); h[0] != 0 || h[1] != 1 || h[2] != 1 || h[3] != 2 {
+		t.Errorf("HistogramRange calculated %v", h)
+	}
+}
+
+func TestStatsInfinite(t *testing.T) {
+	// Exponent2D with a negative exponent gives +Inf where its source is -1
+	c := NewConstGenerator(-1.0)
+	exp := NewExponent2D(&c, -1.0)
+	values := []float64{0.0, 1.0, exp.Get2D(0.0, 0.0), math.Inf(-1)}
+	s := NewStats(values, 4)
+	if s.Count != 2 || s.Infs != 2 || s.Min != 0.0 || s.Max != 1.0 || s.Mean != 0.5 {
+		t.Errorf("Stats of infinite values: %d values, %d infs, %f..%f, mean %f", s.Count, s.Infs, s.Min, s.Max, s.Mean)
+	}
+	if s.Histogram[0] != 1 || s.Histogram[3] != 1 {
+		t.Errorf("Stats calculated the histogram %v", s.Histogram)
+	}
+
+	if h := s.HistogramRange(-math.MaxFloat64, math.MaxFloat64, 4); h[0]+h[1]+h[2]+h[3] != 2 {
+		t.Errorf("HistogramRange over every float64 calculated %v", h)
+	}
+}
+
+func TestBuilderMinMaxNegative(t *testing.T) {
+	b := NewBuilder2D(&ConstGenerator{-0.5}, 4, 4)
+	b.Build()
+	min, max := b.GetMinMax()
+	if min != -0.5 || max != -0.5 {
+		t.Errorf("GetMinMax returned %f..%f for all -0.5 values", min, max)
+	}
+	if s := b.Stats(4); s.Min != -0.5 || s.Max != -0.5 || s.Histogram[3] != 16 {
+		t.Errorf("Stats returned %f..%f and %v for all -0.5 values", s.Min, s.Max, s.Histogram)
+	}
+}
+
+func TestSampleStats2D(t *testing.T) {
+	perlin := NewPerlinGeneratorSeed(1)
+	s := SampleStats2D(&perlin, Builder2DBounds{-50.0, -50.0, 50.0, 50.0}, 20000, NewPCG32(1), 10)
+	if s.Count != 20000 || s.Min < -1.0 || s.Max > 1.0 || math.Abs(s.Mean) > 0.15 {
+		t.Errorf("SampleStats2D of perlin noise: %d values, %f..%f, mean %f", s.Count, s.Min, s.Max, s.Mean)
+	}
+	if s := SampleStats2D(&perlin, Builder2DBounds{-50.0, -50.0, 50.0, 50.0}, -1, NewPCG32(1), 10); s.Count != 0 {
+		t.Errorf("SampleStats2D with -1 samples counted %d values", s.Count)
+	}
+}
+
+func TestSampleStats3D(t *testing.T) {
+	// the attenuated 3D perlin noise can slightly overshoot -1..1
+	perlin := NewPerlinGeneratorSeed(1)
+	perlin.Quality = QualityStandard
+	min, max := Vec3f{-50.0, -50.0, -50.0}, Vec3f{50.0, 50.0, 50.0}
+	s := SampleStats3D(&perlin, min, max, 20000, NewPCG32(1), 10)
+	if s.Count != 20000 || s.Min < -1.0 || s.Max > 1.0 || math.Abs(s.Mean) > 0.15 {
+		t.Errorf("SampleStats3D of perlin noise: %d values, %f..%f, mean %f", s.Count, s.Min, s.Max, s.Mean)
+	}
+
+	// the points have to stay inside the box
+	var box boxCheck3D
+	box.min, box.max = min, max
+	SampleStats3D(&box, min, max, 1000, NewPCG32(2), 0)
+	if box.outside > 0 {
+		t.Errorf("SampleStats3D sampled %d points outside of the box", box.outside)
+	}
+
+	if s := SampleStats3D(&perlin, min, max, 0, NewPCG32(1), 10); s.Count != 0 || s.Min != 0 || s.Max != 0 {
+		t.Errorf("SampleStats3D with 0 samples returned %d values, %f..%f", s.Count, s.Min, s.Max)
+	}
+}
+
+// boxCheck3D counts the points it gets sampled at that are outside of a box.
+type boxCheck3D struct {
+	min, max Vec3f
+	outside  int
+}
+
+func (b *boxCheck3D) Get3D(x, y, z float64) float64 {
+	if x < b.min.X || x > b.max.X || y < b.min.Y || y > b.max.Y || z < b.min.Z || z > b.max.Z {
+		b.outside++
+	}
+	return 0.0
+}
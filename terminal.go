@@ -0,0 +1,151 @@
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+/*
+
+This module draws the values of a Builder2D on a terminal using 24-bit ANSI
+colors, which most terminal emulators support these days, including over SSH.
+
+Each character cell shows two values: the upper half block character is drawn
+in the color of the upper value over a background in the color of the lower
+one. Terminal cells are about twice as tall as they are wide, so the values
+come out roughly square:
+
+  width, height := noisey.TerminalFit(bounds, noisey.TerminalColumns(os.Stdout), 0)
+  builder := noisey.NewBuilder2D(fbm, width, height)
+  builder.Bounds = bounds
+  builder.Build()
+  builder.WriteANSI(os.Stdout, noisey.TerrainGradient)
+
+*/
+
+import (
+	"bufio"
+	"image/color"
+	"io"
+	"os"
+	"strconv"
+)
+
+const (
+	// the upper half block character
+	ansiUpperHalf = "▀"
+
+	// the default size of a terminal when it can't be queried
+	defaultTerminalColumns = 80
+	defaultTerminalRows    = 24
+)
+
+// WriteANSI writes the Values to w as rows of half block characters colored
+// with 24-bit ANSI escape codes picked from the gradient. Every row of text
+// holds two rows of Values; if Height is odd, the lower half of the last row
+// is left in the terminal's background color.
+func (b *Builder2D) WriteANSI(w io.Writer, gradient ColorGradient) error {
+	out := bufio.NewWriter(w)
+	buf := make([]byte, 0, 64)
+
+	for y := 0; y < b.Height; y += 2 {
+		var lastFg, lastBg color.RGBA
+		haveFg, haveBg := false, false
+		for x := 0; x < b.Width; x++ {
+			buf = buf[:0]
+
+			fg := gradient.Color(b.Values[y*b.Width+x])
+			if !haveFg || fg != lastFg {
+				buf = appendANSIColor(buf, 38, fg)
+				lastFg, haveFg = fg, true
+			}
+
+			if y+1 < b.Height {
+				bg := gradient.Color(b.Values[(y+1)*b.Width+x])
+				if !haveBg || bg != lastBg {
+					buf = appendANSIColor(buf, 48, bg)
+					lastBg, haveBg = bg, true
+				}
+			}
+
+			buf = append(buf, ansiUpperHalf...)
+			out.Write(buf)
+		}
+		out.WriteString("\x1b[0m\n")
+	}
+
+	return out.Flush()
+}
+
+// appendANSIColor appends the escape code setting the foreground (38) or
+// background (48) color to buf.
+func appendANSIColor(buf []byte, layer int, c color.RGBA) []byte {
+	buf = append(buf, "\x1b["...)
+	buf = strconv.AppendInt(buf, int64(layer), 10)
+	buf = append(buf, ";2;"...)
+	buf = strconv.AppendInt(buf, int64(c.R), 10)
+	buf = append(buf, ';')
+	buf = strconv.AppendInt(buf, int64(c.G), 10)
+	buf = append(buf, ';')
+	buf = strconv.AppendInt(buf, int64(c.B), 10)
+	return append(buf, 'm')
+}
+
+// TerminalFit returns the Builder2D size that fills the given number of
+// terminal columns while keeping the aspect ratio of bounds. If rows is
+// positive the size is shrunk so that the image fits in that many rows of text.
+func TerminalFit(bounds Builder2DBounds, columns int, rows int) (width int, height int) {
+	xExtent := bounds.MaxX - bounds.MinX
+	yExtent := bounds.MaxY - bounds.MinY
+	if columns < 1 {
+		columns = 1
+	}
+	if xExtent <= 0.0 || yExtent <= 0.0 {
+		return columns, columns
+	}
+
+	// every column is one value wide and every row two values tall
+	width = columns
+	height = int(float64(width)*yExtent/xExtent + 0.5)
+	if rows > 0 && height > rows*2 {
+		height = rows * 2
+		width = int(float64(height)*xExtent/yExtent + 0.5)
+	}
+
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+	return
+}
+
+// TerminalColumns returns the width of the terminal f is attached to. If that
+// can't be determined, the COLUMNS environment variable is used and failing
+// that, 80.
+func TerminalColumns(f *os.File) int {
+	columns, _ := TerminalSize(f)
+	return columns
+}
+
+// TerminalSize returns the number of columns and rows of the terminal f is
+// attached to. If that can't be determined, the COLUMNS and LINES environment
+// variables are used and failing those, 80x24.
+func TerminalSize(f *os.File) (columns int, rows int) {
+	columns, rows = terminalSize(f)
+	if columns <= 0 {
+		columns = envInt("COLUMNS", defaultTerminalColumns)
+	}
+	if rows <= 0 {
+		rows = envInt("LINES", defaultTerminalRows)
+	}
+	return
+}
+
+// envInt returns the positive integer in the environment variable name or def.
+func envInt(name string, def int) int {
+	v, err := strconv.Atoi(os.Getenv(name))
+	if err != nil || v <= 0 {
+		return def
+	}
+	return v
+}
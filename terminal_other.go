@@ -0,0 +1,17 @@
+//go:build !linux && !darwin && !freebsd
+// +build !linux,!darwin,!freebsd
+
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+import (
+	"os"
+)
+
+// terminalSize can't query the terminal on this platform, so TerminalSize
+// falls back on the environment.
+func terminalSize(f *os.File) (columns int, rows int) {
+	return 0, 0
+}
@@ -0,0 +1,42 @@
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+import (
+	"bytes"
+	"image/color"
+	"testing"
+)
+
+func TestWriteANSI(t *testing.T) {
+	gradient := ColorGradient{
+		{-1.0, color.RGBA{0, 0, 0, 255}},
+		{1.0, color.RGBA{255, 255, 255, 255}},
+	}
+	b := NewBuilder2D(nil, 2, 3)
+	copy(b.Values, []float64{1.0, 1.0, -1.0, 1.0, -1.0, -1.0})
+
+	var out bytes.Buffer
+	err := b.WriteANSI(&out, gradient)
+	if err != nil {
+		t.Fatalf("WriteANSI failed: %v", err)
+	}
+
+	expected := "\x1b[38;2;255;255;255m\x1b[48;2;0;0;0m▀\x1b[48;2;255;255;255m▀\x1b[0m\n" +
+		"\x1b[38;2;0;0;0m▀▀\x1b[0m\n"
+	if out.String() != expected {
+		t.Errorf("WriteANSI wrote %q; expected %q", out.String(), expected)
+	}
+}
+
+func TestTerminalFit(t *testing.T) {
+	w, h := TerminalFit(Builder2DBounds{0.0, 0.0, 4.0, 2.0}, 80, 0)
+	if w != 80 || h != 40 {
+		t.Errorf("TerminalFit returned %dx%d; expected 80x40", w, h)
+	}
+	w, h = TerminalFit(Builder2DBounds{0.0, 0.0, 4.0, 2.0}, 80, 10)
+	if w != 40 || h != 20 {
+		t.Errorf("TerminalFit returned %dx%d with 10 rows; expected 40x20", w, h)
+	}
+}
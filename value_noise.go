@@ -0,0 +1,106 @@
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+/*
+
+Value noise assigns a random value to every integer lattice point and then
+interpolates between them. It is cheaper than gradient noise like Perlin, but
+looks blockier since the extremes always lie on the lattice points.
+
+The Quality field picks the s-curve used between the lattice points. There is
+no attenuated blend for value noise, so QualityAttenuated interpolates exactly
+like QualityBest (quintic); new generators use QualityStandard (cubic).
+
+Reference material:
+* http://libnoise.sourceforge.net/glossary/#valuenoise
+* http://www.scratchapixel.com/lessons/procedural-generation-virtual-worlds/procedural-patterns-noise-part-1
+
+*/
+
+import (
+	"math"
+)
+
+// ValueNoiseGenerator stores the state information for generating value noise.
+type ValueNoiseGenerator struct {
+	Rng          RandomSource // random number generator interface; nil unless built with NewValueNoiseGenerator()
+	Permutations []int        // the random permutation table
+	Values       []float64    // the random values in -1..1 assigned to the lattice points
+	Quality      NoiseQuality // the interpolation used between lattice points; QualityAttenuated acts as QualityBest
+}
+
+// NewValueNoiseGenerator creates a new state object for the value noise generator.
+// Quality defaults to QualityStandard; QualityAttenuated has no meaning for value
+// noise and gets treated as QualityBest.
+func NewValueNoiseGenerator(rng RandomSource) (vg ValueNoiseGenerator) {
+	vg.Rng = rng
+	vg.setTables(rng)
+	vg.Quality = QualityStandard
+	return
+}
+
+// NewValueNoiseGeneratorSeed creates a new state object for the value noise
+// generator whose tables are built from seed. This produces the same generator as
+// passing NewPCG32(seed) to NewValueNoiseGenerator() without keeping the RNG around.
+func NewValueNoiseGeneratorSeed(seed int64) (vg ValueNoiseGenerator) {
+	vg.Reseed(seed)
+	vg.Quality = QualityStandard
+	return
+}
+
+// Reseed rebuilds the permutation and value tables from seed, the same way
+// NewValueNoiseGeneratorSeed() does, and drops the Rng.
+func (vg *ValueNoiseGenerator) Reseed(seed int64) {
+	vg.Rng = nil
+	vg.setTables(NewPCG32(seed))
+}
+
+// setTables builds the permutation and value tables from the random number generator.
+func (vg *ValueNoiseGenerator) setTables(rng RandomSource) {
+	vg.Permutations = rng.Perm(tableSize)
+	vg.Values = make([]float64, tableSize)
+	for i := range vg.Values {
+		vg.Values[i] = rng.Float64()*2.0 - 1.0
+	}
+}
+
+func (vg *ValueNoiseGenerator) getValue2(x, y int) float64 {
+	i := vg.Permutations[(vg.Permutations[x&0xFF]+y)&0xFF]
+	return vg.Values[i]
+}
+
+func (vg *ValueNoiseGenerator) getValue3(x, y, z int) float64 {
+	i := vg.Permutations[(vg.Permutations[(vg.Permutations[x&0xFF]+y)&0xFF]+z)&0xFF]
+	return vg.Values[i]
+}
+
+// Get2D calculates the value noise at a given 2D coordinate
+func (vg *ValueNoiseGenerator) Get2D(x, y float64) float64 {
+	floored := Vec2f{math.Floor(x), math.Floor(y)}
+	x0, y0 := int(floored.X), int(floored.Y)
+
+	sx := vg.Quality.sCurve(x - floored.X)
+	sy := vg.Quality.sCurve(y - floored.Y)
+
+	v0 := lerp(vg.getValue2(x0, y0), vg.getValue2(x0+1, y0), sx)
+	v1 := lerp(vg.getValue2(x0, y0+1), vg.getValue2(x0+1, y0+1), sx)
+	return lerp(v0, v1, sy)
+}
+
+// Get3D calculates the value noise at a given 3D coordinate
+func (vg *ValueNoiseGenerator) Get3D(x, y, z float64) float64 {
+	floored := Vec3f{math.Floor(x), math.Floor(y), math.Floor(z)}
+	x0, y0, z0 := int(floored.X), int(floored.Y), int(floored.Z)
+
+	sx := vg.Quality.sCurve(x - floored.X)
+	sy := vg.Quality.sCurve(y - floored.Y)
+	sz := vg.Quality.sCurve(z - floored.Z)
+
+	v00 := lerp(vg.getValue3(x0, y0, z0), vg.getValue3(x0+1, y0, z0), sx)
+	v10 := lerp(vg.getValue3(x0, y0+1, z0), vg.getValue3(x0+1, y0+1, z0), sx)
+	v01 := lerp(vg.getValue3(x0, y0, z0+1), vg.getValue3(x0+1, y0, z0+1), sx)
+	v11 := lerp(vg.getValue3(x0, y0+1, z0+1), vg.getValue3(x0+1, y0+1, z0+1), sx)
+	return lerp(lerp(v00, v10, sy), lerp(v01, v11, sy), sz)
+}
@@ -0,0 +1,92 @@
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// newValueNoise creates a value noise generator seeded through math/rand.
+func newValueNoise(seed int64) ValueNoiseGenerator {
+	return NewValueNoiseGenerator(rand.New(rand.NewSource(seed)))
+}
+
+var valueNoiseQualities = []NoiseQuality{QualityAttenuated, QualityFast, QualityStandard, QualityBest}
+
+func TestValueNoiseRange(t *testing.T) {
+	for _, q := range valueNoiseQualities {
+		vg := newValueNoise(1)
+		vg.Quality = q
+		for i := 0; i < 5000; i++ {
+			x := float64(i)*0.137 - 300.0
+			y := float64(i)*0.291 - 500.0
+			z := float64(i)*0.053 + 40.0
+			v2, v3 := vg.Get2D(x, y), vg.Get3D(x, y, z)
+			if v2 < -1.0 || v2 > 1.0 || v3 < -1.0 || v3 > 1.0 {
+				t.Fatalf("Quality %d: value noise out of range at (%v, %v, %v): %v %v", q, x, y, z, v2, v3)
+			}
+		}
+	}
+}
+
+func TestValueNoiseSeed(t *testing.T) {
+	a := newValueNoise(42)
+	b := newValueNoise(42)
+	c := newValueNoise(43)
+	differs := false
+	for i := 0; i < 100; i++ {
+		x, y, z := float64(i)*0.73, float64(i)*-1.31, float64(i)*0.17
+		if a.Get2D(x, y) != b.Get2D(x, y) || a.Get3D(x, y, z) != b.Get3D(x, y, z) {
+			t.Fatalf("The same seed gave different values at (%v, %v, %v)", x, y, z)
+		}
+		if a.Get2D(x, y) != c.Get2D(x, y) {
+			differs = true
+		}
+	}
+	if !differs {
+		t.Error("Different seeds gave the same value noise.")
+	}
+}
+
+func TestValueNoiseContinuity(t *testing.T) {
+	// the values on both sides of a lattice line must meet, and at the lattice
+	// points the noise must be the value assigned to that point
+	const eps = 1e-9
+	for _, q := range valueNoiseQualities {
+		vg := newValueNoise(7)
+		vg.Quality = q
+		for cx := -3; cx <= 3; cx++ {
+			for _, f := range []float64{0.1, 0.5, 0.9} {
+				x, y := float64(cx), float64(cx)*0.5+f
+				if d := math.Abs(vg.Get2D(x-eps, y) - vg.Get2D(x, y)); d > 1e-6 {
+					t.Errorf("Quality %d: 2D jump of %v across x=%v", q, d, x)
+				}
+				if d := math.Abs(vg.Get2D(y, x-eps) - vg.Get2D(y, x)); d > 1e-6 {
+					t.Errorf("Quality %d: 2D jump of %v across y=%v", q, d, x)
+				}
+				if d := math.Abs(vg.Get3D(y, f, x-eps) - vg.Get3D(y, f, x)); d > 1e-6 {
+					t.Errorf("Quality %d: 3D jump of %v across z=%v", q, d, x)
+				}
+			}
+			if v := vg.Get2D(float64(cx), float64(-cx)); v != vg.getValue2(cx, -cx) {
+				t.Errorf("Quality %d: Get2D at lattice point (%d, %d) is %v, expected %v", q, cx, -cx, v, vg.getValue2(cx, -cx))
+			}
+		}
+	}
+}
+
+func TestValueNoiseAttenuatedIsBest(t *testing.T) {
+	attenuated := newValueNoise(3)
+	attenuated.Quality = QualityAttenuated
+	best := newValueNoise(3)
+	best.Quality = QualityBest
+	for i := 0; i < 100; i++ {
+		x, y, z := float64(i)*0.37, float64(i)*0.61, float64(i)*0.13
+		if attenuated.Get2D(x, y) != best.Get2D(x, y) || attenuated.Get3D(x, y, z) != best.Get3D(x, y, z) {
+			t.Fatalf("QualityAttenuated doesn't match QualityBest at (%v, %v, %v)", x, y, z)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+/*
+
+This module provides a watcher that keeps a built NoiseJSON bank in sync with
+a configuration file on disk. It polls the file, and every file it includes,
+with os.Stat() checks so that no platform specific file notification library
+is needed.
+
+A quick sample of what this looks like is here:
+
+  watcher, err := noisey.NewNoiseJSONWatcher("noise.json", nil)
+  if err != nil {
+    panic(err)
+  }
+  watcher.Subscribe(func(bank *noisey.NoiseJSON, err error) {
+    if err != nil {
+      fmt.Printf("Keeping the old noise bank: %v\n", err)
+      return
+    }
+    fmt.Printf("Noise bank reloaded!\n")
+  })
+  watcher.Start(time.Second)
+  defer watcher.Stop()
+
+  basic := watcher.Bank().GetGenerator("basic")
+
+Clients that need to react on a specific thread (e.g. to upload a new OpenGL
+texture) can skip Start() and call Check() from their own loop instead.
+
+*/
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// NoiseJSONWatchFunc is the callback type used to notify subscribers of a
+// NoiseJSONWatcher. On a successful reload bank is the newly built NoiseJSON
+// and err is nil; on a failed reload bank is the last good NoiseJSON that is
+// still in use and err describes why the new file was rejected.
+type NoiseJSONWatchFunc func(bank *NoiseJSON, err error)
+
+// NoiseJSONWatcher polls a NoiseJSON configuration file and rebuilds the
+// sources and generators whenever the file changes.
+type NoiseJSONWatcher struct {
+	// Filename is the path of the configuration file being watched
+	Filename string
+
+	// SeedBuilder is passed to NoiseJSON.BuildSources() on every reload
+	SeedBuilder RandomSeedBuilder
+
+	// mutex guards the fields below so that Bank() can be called while
+	// a reload is happening on another goroutine
+	mutex       sync.RWMutex
+	bank        *NoiseJSON
+	stamps      map[string]fileStamp
+	subscribers []NoiseJSONWatchFunc
+
+	// runMutex guards the polling goroutine state so that Start() and Stop()
+	// can be called more than once, and from several goroutines
+	runMutex sync.Mutex
+	running  bool
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// fileStamp is the information used to detect a change to a watched file.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewNoiseJSONWatcher creates a new watcher for the configuration file and
+// builds the initial NoiseJSON bank from it. An error is returned if the
+// first load fails since there is no last good bank to fall back on.
+func NewNoiseJSONWatcher(filename string, seedBuilder RandomSeedBuilder) (*NoiseJSONWatcher, error) {
+	w := new(NoiseJSONWatcher)
+	w.Filename = filename
+	w.SeedBuilder = seedBuilder
+
+	// stat before loading so that an edit made during the load isn't missed
+	absName, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to resolve the path of %s.\n%v\n", filename, err)
+	}
+	stamps := statFiles([]string{absName})
+
+	bank, err := w.load()
+	if err != nil {
+		return nil, err
+	}
+
+	w.bank = bank
+	w.stamps = restampFiles(stamps, bank.Files())
+	return w, nil
+}
+
+// Bank returns the last NoiseJSON that was successfully built. The returned
+// object is never modified by the watcher; a reload swaps in a new one.
+func (w *NoiseJSONWatcher) Bank() *NoiseJSON {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+	return w.bank
+}
+
+// Subscribe registers a callback that gets called after every reload attempt.
+// Callbacks are invoked on the goroutine that performed the check.
+func (w *NoiseJSONWatcher) Subscribe(cb NoiseJSONWatchFunc) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.subscribers = append(w.subscribers, cb)
+}
+
+// Updates returns a channel that receives the new bank every time the file
+// is successfully reloaded. The channel is buffered by one and a pending
+// bank is replaced by a newer one so that a slow reader never blocks the watcher.
+func (w *NoiseJSONWatcher) Updates() <-chan *NoiseJSON {
+	ch := make(chan *NoiseJSON, 1)
+	w.Subscribe(func(bank *NoiseJSON, err error) {
+		if err != nil {
+			return
+		}
+		for {
+			select {
+			case ch <- bank:
+				return
+			default:
+				// drop the stale bank waiting in the channel and try again
+				select {
+				case <-ch:
+				default:
+				}
+			}
+		}
+	})
+	return ch
+}
+
+// Check stats the configuration file and all of its included files and reloads
+// them if a modification time or size changed since the last check. It returns true if a reload was
+// attempted. If the new file fails to load or build, the last good bank is
+// kept and the error is returned as well as passed to the subscribers. A file
+// that can't be stat'd, like one that was deleted by an editor saving it,
+// counts as changed, so its error gets delivered the same way once.
+func (w *NoiseJSONWatcher) Check() (bool, error) {
+	w.mutex.RLock()
+	watched := make([]string, 0, len(w.stamps))
+	for f := range w.stamps {
+		watched = append(watched, f)
+	}
+	stamps := statFiles(watched)
+	unchanged := true
+	for f, stamp := range stamps {
+		old := w.stamps[f]
+		if !stamp.modTime.Equal(old.modTime) || stamp.size != old.size {
+			unchanged = false
+		}
+	}
+	w.mutex.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	// build the new bank outside of the lock so that readers are not held up
+	bank, loadErr := w.load()
+
+	w.mutex.Lock()
+	if loadErr == nil {
+		w.bank = bank
+		w.stamps = restampFiles(stamps, bank.Files())
+	} else {
+		// don't retry the broken files until they change again
+		bank = w.bank
+		w.stamps = stamps
+	}
+	subscribers := make([]NoiseJSONWatchFunc, len(w.subscribers))
+	copy(subscribers, w.subscribers)
+	w.mutex.Unlock()
+
+	for _, cb := range subscribers {
+		cb(bank, loadErr)
+	}
+
+	return true, loadErr
+}
+
+// Start launches a goroutine that calls Check() every interval until
+// Stop() is called. Calling Start() on a running watcher does nothing.
+func (w *NoiseJSONWatcher) Start(interval time.Duration) {
+	w.runMutex.Lock()
+	defer w.runMutex.Unlock()
+	if w.running {
+		return
+	}
+	w.running = true
+	w.stop = make(chan struct{})
+	w.done = make(chan struct{})
+
+	go func(stop, done chan struct{}) {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				w.Check()
+			}
+		}
+	}(w.stop, w.done)
+}
+
+// Stop halts the polling goroutine started by Start() and waits for it to exit.
+// Calling Stop() on a watcher that isn't running does nothing.
+func (w *NoiseJSONWatcher) Stop() {
+	w.runMutex.Lock()
+	defer w.runMutex.Unlock()
+	if !w.running {
+		return
+	}
+	close(w.stop)
+	<-w.done
+	w.running = false
+	w.stop = nil
+	w.done = nil
+}
+
+// load reads the configuration file and builds all of the sources and
+// generators in a brand new NoiseJSON object.
+func (w *NoiseJSONWatcher) load() (*NoiseJSON, error) {
+	bank, err := LoadNoiseJSONFile(w.Filename)
+	if err != nil {
+		return nil, err
+	}
+	err = bank.BuildSources(w.SeedBuilder)
+	if err != nil {
+		return nil, err
+	}
+	err = bank.BuildGenerators()
+	if err != nil {
+		return nil, err
+	}
+
+	return bank, nil
+}
+
+// restampFiles returns the stamps for files, reusing the stamps taken before
+// they were loaded and only stat'ing the files that weren't watched before,
+// like newly included ones.
+func restampFiles(before map[string]fileStamp, files []string) map[string]fileStamp {
+	stamps := make(map[string]fileStamp)
+	var added []string
+	for _, f := range files {
+		if stamp, ok := before[f]; ok {
+			stamps[f] = stamp
+		} else {
+			added = append(added, f)
+		}
+	}
+	for f, stamp := range statFiles(added) {
+		stamps[f] = stamp
+	}
+	return stamps
+}
+
+// statFiles returns the current stamps for the files; files that can't be
+// stat'd get an empty stamp so that they are detected when they reappear.
+func statFiles(files []string) map[string]fileStamp {
+	stamps := make(map[string]fileStamp)
+	for _, f := range files {
+		info, err := os.Stat(f)
+		if err != nil {
+			stamps[f] = fileStamp{}
+			continue
+		}
+		stamps[f] = fileStamp{info.ModTime(), info.Size()}
+	}
+	return stamps
+}
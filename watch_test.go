@@ -0,0 +1,197 @@
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const watchJSON = `{
+	"Seeds": { "Default": 1 },
+	"Sources": {
+		"perlin": { "SourceType": "perlin", "Seed": "Default" }
+	},
+	"Generators": [
+		{ "Name": "land", "GeneratorType": "fBm2d", "Sources": ["perlin"], "Octaves": %d, "Persistence": 0.5, "Lacunarity": 2.0, "Frequency": 1.0 }
+	]
+}`
+
+// rewrite replaces the contents of a watched file. The tests always change
+// the size so that the change is seen even if the modification time doesn't.
+func rewrite(t *testing.T, path string, contents string) {
+	err := ioutil.WriteFile(path, []byte(contents), 0644)
+	if err != nil {
+		t.Fatalf("Unable to write %s: %v", path, err)
+	}
+}
+
+func TestWatcherReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "noisey")
+	if err != nil {
+		t.Fatalf("Unable to create a temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "noise.json")
+	rewrite(t, path, fmt.Sprintf(watchJSON, 2))
+
+	w, err := NewNoiseJSONWatcher(path, nil)
+	if err != nil {
+		t.Fatalf("Unable to create the watcher: %v", err)
+	}
+	var calls int
+	var lastErr error
+	w.Subscribe(func(bank *NoiseJSON, err error) {
+		calls++
+		lastErr = err
+	})
+	updates := w.Updates()
+
+	if reloaded, err := w.Check(); reloaded || err != nil {
+		t.Fatalf("The unchanged file was reloaded: %v", err)
+	}
+
+	first := w.Bank()
+	rewrite(t, path, fmt.Sprintf(watchJSON, 12))
+	if reloaded, err := w.Check(); !reloaded || err != nil {
+		t.Fatalf("The changed file wasn't reloaded: %v", err)
+	}
+	if w.Bank() == first || w.Bank().Generators[0].Octaves != 12 || calls != 1 || lastErr != nil {
+		t.Errorf("The reloaded bank wasn't swapped in")
+	}
+	select {
+	case bank := <-updates:
+		if bank != w.Bank() {
+			t.Errorf("Updates sent a different bank than the reloaded one")
+		}
+	default:
+		t.Errorf("Updates didn't receive the reloaded bank")
+	}
+
+	// a broken file keeps the last good bank, delivers the error once and
+	// isn't retried until it changes
+	good := w.Bank()
+	rewrite(t, path, `{ "Generators": [ { "Name": "broken", "GeneratorType": "nothing" } ] }`)
+	if reloaded, err := w.Check(); !reloaded || err == nil {
+		t.Fatalf("The broken file wasn't reported")
+	}
+	if w.Bank() != good || calls != 2 || lastErr == nil {
+		t.Errorf("The broken reload replaced the last good bank")
+	}
+	if reloaded, _ := w.Check(); reloaded || calls != 2 {
+		t.Errorf("The unchanged broken file was loaded again")
+	}
+	select {
+	case <-updates:
+		t.Errorf("Updates received a bank for the broken reload")
+	default:
+	}
+}
+
+func TestWatcherNewInclude(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"noise.json": fmt.Sprintf(watchJSON, 2),
+		"extra.json": `{ "Seeds": { "Extra": 2 } }`,
+	})
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "noise.json")
+	extra := filepath.Join(dir, "extra.json")
+
+	w, err := NewNoiseJSONWatcher(path, nil)
+	if err != nil {
+		t.Fatalf("Unable to create the watcher: %v", err)
+	}
+
+	rewrite(t, path, `{ "Include": ["extra.json"], "Seeds": { "Default": 1 } }`)
+	if reloaded, err := w.Check(); !reloaded || err != nil {
+		t.Fatalf("The file wasn't reloaded with its new include: %v", err)
+	}
+
+	rewrite(t, extra, `{ "Seeds": { "Extra": 12345 } }`)
+	if reloaded, err := w.Check(); !reloaded || err != nil {
+		t.Fatalf("The change to the new include wasn't seen: %v", err)
+	}
+	if w.Bank().Seeds["Extra"] != 12345 {
+		t.Errorf("The new include wasn't reloaded: %v", w.Bank().Seeds)
+	}
+}
+
+func TestRestampFiles(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{"a.json": "{}", "b.json": "{}"})
+	defer os.RemoveAll(dir)
+	a, b := filepath.Join(dir, "a.json"), filepath.Join(dir, "b.json")
+
+	// the stamp taken before a load is kept even if the file changed since,
+	// so that the change is picked up by the next check
+	before := map[string]fileStamp{a: {size: 1}}
+	stamps := restampFiles(before, []string{a, b})
+	if len(stamps) != 2 || stamps[a].size != 1 || stamps[b].size != 2 {
+		t.Errorf("restampFiles returned %v", stamps)
+	}
+}
+
+func TestWatcherDeletedFile(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{"noise.json": fmt.Sprintf(watchJSON, 2)})
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "noise.json")
+
+	w, err := NewNoiseJSONWatcher(path, nil)
+	if err != nil {
+		t.Fatalf("Unable to create the watcher: %v", err)
+	}
+	var errs []error
+	w.Subscribe(func(bank *NoiseJSON, err error) {
+		errs = append(errs, err)
+	})
+	good := w.Bank()
+
+	// the missing file is reported to the subscribers once, like a broken one
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("Unable to remove %s: %v", path, err)
+	}
+	if reloaded, err := w.Check(); !reloaded || err == nil {
+		t.Fatalf("The deleted file wasn't reported")
+	}
+	if reloaded, err := w.Check(); reloaded || err != nil {
+		t.Errorf("The deleted file was reported again: %v", err)
+	}
+	if len(errs) != 1 || errs[0] == nil || w.Bank() != good {
+		t.Errorf("The subscribers got %v for the deleted file", errs)
+	}
+
+	// and it gets reloaded once it's back
+	rewrite(t, path, fmt.Sprintf(watchJSON, 5))
+	if reloaded, err := w.Check(); !reloaded || err != nil {
+		t.Fatalf("The restored file wasn't reloaded: %v", err)
+	}
+	if len(errs) != 2 || errs[1] != nil || w.Bank().Generators[0].Octaves != 5 {
+		t.Errorf("The restored file wasn't swapped in: %v", errs)
+	}
+}
+
+func TestWatcherStartStop(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{"noise.json": fmt.Sprintf(watchJSON, 2)})
+	defer os.RemoveAll(dir)
+
+	w, err := NewNoiseJSONWatcher(filepath.Join(dir, "noise.json"), nil)
+	if err != nil {
+		t.Fatalf("Unable to create the watcher: %v", err)
+	}
+
+	// stopping a watcher that was never started, starting it twice and
+	// stopping it twice must all be safe
+	w.Stop()
+	w.Start(time.Millisecond)
+	w.Start(time.Millisecond)
+	w.Stop()
+	w.Stop()
+
+	// and it can be started again after that
+	w.Start(time.Millisecond)
+	w.Stop()
+}
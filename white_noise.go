@@ -0,0 +1,76 @@
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+/*
+
+White noise returns an uncorrelated random value for every integer coordinate.
+The coordinates are floored and hashed together with a seed, so every point
+inside a unit cell returns the same value, which is useful for blocky detail
+and dithering. Unlike the permutation table based sources, the pattern does
+not repeat every 256 units.
+
+The hash finalizer is the one from SplitMix64:
+* http://xoroshiro.di.unimi.it/splitmix64.c
+
+*/
+
+import (
+	"math"
+)
+
+// WhiteNoiseGenerator stores the state information for generating white noise.
+type WhiteNoiseGenerator struct {
+	Rng  RandomSource // random number generator interface; nil unless built with NewWhiteNoiseGenerator()
+	Seed uint64       // the seed mixed into the coordinate hash
+}
+
+// NewWhiteNoiseGenerator creates a new state object for the white noise generator
+// using the random number generator to pick the hash seed.
+func NewWhiteNoiseGenerator(rng RandomSource) (wg WhiteNoiseGenerator) {
+	wg.Rng = rng
+	wg.Seed = uint64(rng.Float64() * (1 << 53))
+	return
+}
+
+// NewWhiteNoiseGeneratorSeed creates a new state object for the white noise
+// generator that hashes the coordinates with seed.
+func NewWhiteNoiseGeneratorSeed(seed int64) (wg WhiteNoiseGenerator) {
+	wg.Reseed(seed)
+	return
+}
+
+// Reseed sets the seed mixed into the coordinate hash and drops the Rng.
+func (wg *WhiteNoiseGenerator) Reseed(seed int64) {
+	wg.Rng = nil
+	wg.Seed = uint64(seed)
+}
+
+// mixHash64 scrambles the bits of h with the SplitMix64 increment and finalizer.
+func mixHash64(h uint64) uint64 {
+	h += 0x9e3779b97f4a7c15
+	h = (h ^ (h >> 30)) * 0xbf58476d1ce4e5b9
+	h = (h ^ (h >> 27)) * 0x94d049bb133111eb
+	return h ^ (h >> 31)
+}
+
+// hashToUnit turns a 64 bit hash into a float64 in -1..1.
+func hashToUnit(h uint64) float64 {
+	return float64(h>>11)/(1<<53)*2.0 - 1.0
+}
+
+// Get2D calculates the white noise at a given 2D coordinate
+func (wg *WhiteNoiseGenerator) Get2D(x, y float64) float64 {
+	h := mixHash64(wg.Seed ^ uint64(int64(math.Floor(x))))
+	h = mixHash64(h ^ uint64(int64(math.Floor(y))))
+	return hashToUnit(h)
+}
+
+// Get3D calculates the white noise at a given 3D coordinate
+func (wg *WhiteNoiseGenerator) Get3D(x, y, z float64) float64 {
+	h := mixHash64(wg.Seed ^ uint64(int64(math.Floor(x))))
+	h = mixHash64(h ^ uint64(int64(math.Floor(y))))
+	h = mixHash64(h ^ uint64(int64(math.Floor(z))))
+	return hashToUnit(h)
+}
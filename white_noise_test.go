@@ -0,0 +1,72 @@
+package noisey
+
+/* Copyright 2015, Timothy Bogdala <tdb@animal-machine.com>
+See the LICENSE file for more details. */
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// newWhiteNoise creates a white noise generator seeded through math/rand.
+func newWhiteNoise(seed int64) WhiteNoiseGenerator {
+	return NewWhiteNoiseGenerator(rand.New(rand.NewSource(seed)))
+}
+
+func TestWhiteNoiseRange(t *testing.T) {
+	wg := newWhiteNoise(1)
+	for i := 0; i < 5000; i++ {
+		x, y, z := float64(i)*1.37-3000.0, float64(i)*-2.91, float64(i)*0.53
+		v2, v3 := wg.Get2D(x, y), wg.Get3D(x, y, z)
+		if v2 < -1.0 || v2 > 1.0 || v3 < -1.0 || v3 > 1.0 {
+			t.Fatalf("White noise out of range at (%v, %v, %v): %v %v", x, y, z, v2, v3)
+		}
+	}
+}
+
+func TestWhiteNoiseSeed(t *testing.T) {
+	a := newWhiteNoise(42)
+	b := newWhiteNoise(42)
+	c := newWhiteNoise(43)
+	differs := false
+	for i := 0; i < 100; i++ {
+		x, y, z := float64(i), float64(-i), float64(i*3)
+		if a.Get2D(x, y) != b.Get2D(x, y) || a.Get3D(x, y, z) != b.Get3D(x, y, z) {
+			t.Fatalf("The same seed gave different values at (%v, %v, %v)", x, y, z)
+		}
+		if a.Get2D(x, y) != c.Get2D(x, y) {
+			differs = true
+		}
+	}
+	if !differs {
+		t.Error("Different seeds gave the same white noise.")
+	}
+}
+
+func TestWhiteNoiseCells(t *testing.T) {
+	wg := newWhiteNoise(5)
+	for cx := -20; cx < 20; cx++ {
+		for cy := -20; cy < 20; cy++ {
+			x, y := float64(cx), float64(cy)
+			v := wg.Get2D(x, y)
+
+			// every point inside the cell returns the value of its corner
+			if inside := wg.Get2D(x+0.25, y+0.75); inside != v {
+				t.Fatalf("Value inside cell (%d, %d) is %v, expected %v", cx, cy, inside, v)
+			}
+
+			// but the neighbouring integer coordinates are uncorrelated
+			if v == wg.Get2D(x+1, y) || v == wg.Get2D(x, y+1) {
+				t.Fatalf("White noise at (%d, %d) repeats in a neighbouring cell", cx, cy)
+			}
+			if v3 := wg.Get3D(x, y, 0); v3 == wg.Get3D(x, y, 1) || v3 == wg.Get3D(x+1, y, 0) {
+				t.Fatalf("3D white noise at (%d, %d, 0) repeats in a neighbouring cell", cx, cy)
+			}
+		}
+	}
+
+	// the hash isn't based on a 256 entry table, so it doesn't repeat there
+	if wg.Get2D(3, 4) == wg.Get2D(3+256, 4) || wg.Get2D(3, 4) == wg.Get2D(3, 4+256) {
+		t.Error("White noise repeats every 256 units.")
+	}
+}